@@ -0,0 +1,56 @@
+package main
+
+import "sync"
+
+// resettable is implemented by values which can discard whatever they have cached.
+type resettable interface {
+	reset()
+}
+
+// caches holds every cached value created by newCached, so that they can all be reset together.
+var caches []resettable
+
+// cached lazily computes a value derived from the generated data and remembers it.
+//
+// The data only changes when teams are overridden, so derived values only need to be computed
+// once in between. Calling reset causes the value to be recomputed on the next get.
+type cached[T any] struct {
+	mu      sync.Mutex
+	compute func() T
+	value   T
+	valid   bool
+}
+
+// newCached creates a cached value which is computed by the given function on first use.
+func newCached[T any](compute func() T) *cached[T] {
+	c := &cached[T]{compute: compute}
+	caches = append(caches, c)
+	return c
+}
+
+// get returns the cached value, computing it first if necessary.
+func (c *cached[T]) get() T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.valid {
+		c.value = c.compute()
+		c.valid = true
+	}
+	return c.value
+}
+
+// reset discards the cached value so that it is recomputed on the next get.
+func (c *cached[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero T
+	c.value = zero
+	c.valid = false
+}
+
+// resetCaches discards every cached value.
+func resetCaches() {
+	for _, c := range caches {
+		c.reset()
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsAllowedMethods are the methods cross-origin requests may use. They are the safe methods used by this API.
+var corsAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// corsAllowedOrigins returns the origins permitted to make cross-origin requests, read from the
+// comma-separated TEAMS_CORS_ORIGINS environment variable. If it is not set, any origin is permitted.
+func corsAllowedOrigins() []string {
+	return parseOrigins(os.Getenv("TEAMS_CORS_ORIGINS"))
+}
+
+// parseOrigins splits a comma-separated list of origins, ignoring surrounding whitespace and empty
+// entries. An empty list is treated as "*", permitting any origin.
+func parseOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return []string{"*"}
+	}
+	return origins
+}
+
+// cors returns middleware which allows browsers to call the API from the given origins.
+//
+// Requests from a permitted origin have the Access-Control-Allow-Origin header set on their
+// responses. Preflight OPTIONS requests are answered directly: with 204 No Content if the origin
+// and method are permitted, and with 403 Forbidden otherwise.
+func cors(allowedOrigins []string) gin.HandlerFunc {
+	anyOrigin := slices.Contains(allowedOrigins, "*")
+	allowedMethods := strings.Join(corsAllowedMethods, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := anyOrigin || slices.Contains(allowedOrigins, origin)
+		if allowed {
+			if anyOrigin {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Add("Vary", "Origin")
+			}
+			c.Header("Access-Control-Expose-Headers", "ETag, X-Total-Count")
+		}
+
+		requestedMethod := c.GetHeader("Access-Control-Request-Method")
+		if c.Request.Method != http.MethodOptions || requestedMethod == "" {
+			c.Next()
+			return
+		}
+
+		if !allowed || !slices.Contains(corsAllowedMethods, requestedMethod) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", allowedMethods)
+		c.Header("Access-Control-Allow-Headers", "Accept, Content-Type, If-None-Match")
+		c.Header("Access-Control-Max-Age", "600")
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCORSRouter creates a router with CORS middleware permitting the given origins.
+func newCORSRouter(allowedOrigins ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(cors(allowedOrigins))
+	router.GET("/people", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// preflight sends a preflight request for the given origin and method through the router.
+func preflight(router *gin.Engine, origin string, method string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodOptions, "/people", nil)
+	request.Header.Set("Origin", origin)
+	request.Header.Set("Access-Control-Request-Method", method)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestParseOrigins(t *testing.T) {
+	assert.Equal(t, []string{"*"}, parseOrigins(""))
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"},
+		parseOrigins(" https://a.example.com, ,https://b.example.com "))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSRouter("https://dashboard.example.com")
+
+	response := preflight(router, "https://dashboard.example.com", http.MethodGet)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "https://dashboard.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD, OPTIONS", response.Header().Get("Access-Control-Allow-Methods"))
+	assert.Contains(t, response.Header().Get("Access-Control-Allow-Headers"), "If-None-Match")
+	assert.Equal(t, "Origin", response.Header().Get("Vary"))
+}
+
+func TestCORSPreflightRejected(t *testing.T) {
+	router := newCORSRouter("https://dashboard.example.com")
+
+	response := preflight(router, "https://evil.example.com", http.MethodGet)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+
+	response = preflight(router, "https://dashboard.example.com", http.MethodDelete)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	router := newCORSRouter("*")
+
+	request := httptest.NewRequest(http.MethodGet, "/people", nil)
+	request.Header.Set("Origin", "https://dashboard.example.com")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "*", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "ETag, X-Total-Count", response.Header().Get("Access-Control-Expose-Headers"))
+}
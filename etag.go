@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+// dataETag is an entity tag identifying the current version of the generated data.
+//
+// It covers both people and teams, because the people endpoints can be filtered by team.
+var dataETag = newCached(func() string {
+	data, err := json.Marshal(struct {
+		People any
+		Teams  any
+	}{generated_data.People, currentTeams.get()})
+	if err != nil {
+		log.Panicf("failed to marshal generated data: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+})
+
+// notModified sets the ETag header on the response and reports whether the request's If-None-Match
+// header matches it. If it does, the response status is set to 304 Not Modified and the caller
+// should not write a body.
+//
+// The representation distinguishes responses to the same URL which encode the data differently, such
+// as pretty and compact JSON, so that each has its own strong entity tag. It is empty if there is
+// only one representation.
+func notModified(c *gin.Context, representation string) bool {
+	etag := dataETag.get()
+	if representation != "" {
+		etag = strings.TrimSuffix(etag, `"`) + "-" + representation + `"`
+	}
+	c.Header("ETag", etag)
+
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+// writeCSV responds with the given records as a CSV attachment with the given filename.
+//
+// The first row contains the names of the record type's fields, and each subsequent row contains
+// the field values of one record. String slice fields are joined with semicolons.
+func writeCSV[T any](c *gin.Context, filename string, records []T) {
+	recordType := reflect.TypeFor[T]()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+
+	header := make([]string, recordType.NumField())
+	for i := range header {
+		header[i] = recordType.Field(i).Name
+	}
+	_ = writer.Write(header)
+
+	for _, record := range records {
+		value := reflect.ValueOf(record)
+		row := make([]string, value.NumField())
+		for i := range row {
+			row[i] = csvValue(value.Field(i))
+		}
+		_ = writer.Write(row)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// writeNDJSON responds with the given records as newline-delimited JSON, with each record encoded as
+// a JSON object on its own line. The response is flushed after each record rather than buffered.
+func writeNDJSON[T any](c *gin.Context, records []T) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeJSON responds with the given value as JSON, indented unless the client has asked for compact output.
+//
+// Compact output is chosen by a pretty query parameter of false, or, when there is no such parameter,
+// by an Accept header asking for application/json rather than HTML. Browsers therefore get indented
+// output by default, while programmatic clients can save the bytes.
+func writeJSON(c *gin.Context, status int, value any) {
+	if jsonRepresentation(c) == "pretty" {
+		c.IndentedJSON(status, value)
+	} else {
+		c.JSON(status, value)
+	}
+}
+
+// jsonRepresentation returns the name of the JSON representation chosen for the request, "pretty"
+// or "compact", for use in its entity tag. As the choice may depend on the Accept header, it also
+// adds Accept to the Vary header, so that a 304 Not Modified response carries it too.
+func jsonRepresentation(c *gin.Context) string {
+	header := c.Writer.Header()
+	if !slices.Contains(header.Values("Vary"), "Accept") {
+		header.Add("Vary", "Accept")
+	}
+	if prettyJSON(c) {
+		return "pretty"
+	}
+	return "compact"
+}
+
+// prettyJSON reports whether the JSON response to the request should be indented.
+//
+// A pretty query parameter which is not a boolean is ignored.
+func prettyJSON(c *gin.Context) bool {
+	if value, ok := c.GetQuery("pretty"); ok {
+		if pretty, err := strconv.ParseBool(value); err == nil {
+			return pretty
+		}
+	}
+
+	acceptsJSON, acceptsHTML := false, false
+	for _, mediaRange := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/json":
+			acceptsJSON = true
+		case "text/html":
+			acceptsHTML = true
+		}
+	}
+	return !acceptsJSON || acceptsHTML
+}
+
+// csvValue formats a single field value for inclusion in a CSV row.
+func csvValue(value reflect.Value) string {
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String {
+		elements := make([]string, value.Len())
+		for i := range elements {
+			elements[i] = value.Index(i).String()
+		}
+		return strings.Join(elements, ";")
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// dotEscaper escapes the characters which are special within a quoted DOT string.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// dotQuote returns the given string as a quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// getTeamsDOT responds with a Graphviz DOT graph of team membership.
+//
+// Teams and people are both nodes, with an edge from each team to each of its members. Node IDs are
+// prefixed with team: or person: so that a team and a person with the same name remain distinct, and
+// people are identified by their ID if they have one, so that people with the same name do too.
+func getTeamsDOT(c *gin.Context) {
+	var sb strings.Builder
+	sb.WriteString("digraph teams {\n")
+
+	people := make(map[string]bool)
+	for _, person := range generated_data.People {
+		people[personKey(person)] = true
+		fmt.Fprintf(&sb, "    %s [label=%s];\n", dotQuote("person:"+personKey(person)), dotQuote(person.Name))
+	}
+	for _, team := range currentTeams.get() {
+		fmt.Fprintf(&sb, "    %s [label=%s, shape=box];\n", dotQuote("team:"+team.Name), dotQuote(team.Name))
+		for _, member := range team.Members {
+			key := member
+			if person, ok := resolveMember(member); ok {
+				key = personKey(person)
+			}
+			if !people[key] {
+				people[key] = true
+				fmt.Fprintf(&sb, "    %s [label=%s, style=dashed];\n", dotQuote("person:"+key), dotQuote(member))
+			}
+			fmt.Fprintf(&sb, "    %s -> %s;\n", dotQuote("team:"+team.Name), dotQuote("person:"+key))
+		}
+	}
+
+	sb.WriteString("}\n")
+	c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(sb.String()))
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestGetPeopleCSV(t *testing.T) {
+	people := []model.Person{
+		{Name: "Smith, Alice", Email: "alice@example.com", Role: "Engineer"},
+		{Name: `Bob "Bobby" Jones`, SlackChannel: "D002"},
+	}
+	seedData(t, people, testTeams)
+
+	for _, target := range []string{"/people.csv", "/people?format=csv"} {
+		t.Run(target, func(t *testing.T) {
+			response := performRequest(t, http.MethodGet, target)
+			require.Equal(t, http.StatusOK, response.Code)
+			assert.Equal(t, "text/csv; charset=utf-8", response.Header().Get("Content-Type"))
+			assert.Equal(t, "attachment; filename=people.csv", response.Header().Get("Content-Disposition"))
+
+			assert.Contains(t, response.Body.String(), `"Smith, Alice"`)
+
+			rows, err := csv.NewReader(response.Body).ReadAll()
+			require.NoError(t, err)
+			assert.Equal(t, [][]string{
+				{"Name", "ID", "Nickname", "Email", "Role", "GithubHandle", "SlackChannel"},
+				{"Smith, Alice", "", "", "alice@example.com", "Engineer", "", ""},
+				{`Bob "Bobby" Jones`, "", "", "", "", "", "D002"},
+			}, rows)
+		})
+	}
+}
+
+func TestGetPeopleUnsupportedFormat(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?format=xml")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestGetTeamsDOT(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}, {Name: `Bob "Bobby" Jones`}}
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice Smith", `Bob "Bobby" Jones`}},
+		{Name: `Data\Science`, Members: []string{"Alice Smith", "Carol White"}},
+	}
+	seedData(t, people, teams)
+
+	response := performRequest(t, http.MethodGet, "/teams.dot")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/vnd.graphviz; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, `digraph teams {
+    "person:Alice Smith" [label="Alice Smith"];
+    "person:Bob \"Bobby\" Jones" [label="Bob \"Bobby\" Jones"];
+    "team:Platform" [label="Platform", shape=box];
+    "team:Platform" -> "person:Alice Smith";
+    "team:Platform" -> "person:Bob \"Bobby\" Jones";
+    "team:Data\\Science" [label="Data\\Science", shape=box];
+    "team:Data\\Science" -> "person:Alice Smith";
+    "person:Carol White" [label="Carol White", style=dashed];
+    "team:Data\\Science" -> "person:Carol White";
+}
+`, response.Body.String())
+}
+
+// readNDJSON reads newline-delimited JSON from the response, unmarshalling each line into a record.
+func readNDJSON[T any](t *testing.T, response *httptest.ResponseRecorder) []T {
+	t.Helper()
+	var records []T
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		var record T
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestGetPeopleNDJSON(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?format=ndjson")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+	assert.True(t, response.Flushed)
+	assert.Equal(t, testPeople, readNDJSON[model.Person](t, response))
+}
+
+func TestGetTeamsNDJSON(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams?format=ndjson")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+	assert.Equal(t, testTeams, readNDJSON[model.Team](t, response))
+
+	response = performRequest(t, http.MethodGet, "/teams?format=csv")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestJSONIsIndentedByDefault(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, target := range []string{"/people", "/teams", "/teams?pretty=true"} {
+		response := performRequest(t, http.MethodGet, target)
+		require.Equal(t, http.StatusOK, response.Code, target)
+		assert.True(t, strings.HasPrefix(response.Body.String(), "[\n    {\n"), target)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/people", nil)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/json;q=0.9,*/*;q=0.8")
+	response := serveRequest(t, request)
+	assert.Contains(t, response.Body.String(), "\n    ")
+}
+
+func TestCompactJSON(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	requests := map[string]*http.Request{
+		"pretty=false": httptest.NewRequest(http.MethodGet, "/teams?pretty=false", nil),
+		"accept":       httptest.NewRequest(http.MethodGet, "/people", nil),
+	}
+	requests["accept"].Header.Set("Accept", "application/json")
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			response := serveRequest(t, request)
+			require.Equal(t, http.StatusOK, response.Code)
+			assert.NotContains(t, response.Body.String(), "\n")
+			assert.NotContains(t, response.Body.String(), "  ")
+			assert.Contains(t, response.Header().Values("Vary"), "Accept")
+			assert.True(t, json.Valid(response.Body.Bytes()))
+		})
+	}
+}
+
+func TestConditionalGetOfJSONRepresentations(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, target := range []string{"/people", "/teams", "/org"} {
+		t.Run(target, func(t *testing.T) {
+			pretty := performRequest(t, http.MethodGet, target)
+			require.Equal(t, http.StatusOK, pretty.Code)
+			compact := performRequest(t, http.MethodGet, target+"?pretty=false")
+			require.Equal(t, http.StatusOK, compact.Code)
+			assert.NotEqual(t, pretty.Header().Get("ETag"), compact.Header().Get("ETag"))
+
+			request := httptest.NewRequest(http.MethodGet, target+"?pretty=false", nil)
+			request.Header.Set("If-None-Match", pretty.Header().Get("ETag"))
+			response := serveRequest(t, request)
+			assert.Equal(t, http.StatusOK, response.Code, "a compact response is not matched by the tag of a pretty one")
+
+			request = httptest.NewRequest(http.MethodGet, target, nil)
+			request.Header.Set("Accept", "application/json")
+			request.Header.Set("If-None-Match", compact.Header().Get("ETag"))
+			response = serveRequest(t, request)
+			assert.Equal(t, http.StatusNotModified, response.Code)
+			assert.ElementsMatch(t, []string{"Accept", "Accept-Encoding"}, response.Header().Values("Vary"))
+		})
+	}
+}
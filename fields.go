@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rvodden/teams/model"
+)
+
+// fieldSelection is the subset of a struct's fields chosen by a fields query parameter.
+type fieldSelection []reflect.StructField
+
+// parseFields parses a comma-separated list of field names into a selection of the fields of T.
+//
+// A field may be named by its JSON name or its YAML name, ignoring case. Any names which do not
+// match a field are reported together in the error.
+func parseFields[T any](spec string) (fieldSelection, error) {
+	entityType := reflect.TypeFor[T]()
+
+	var selection fieldSelection
+	var unknown []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		field, ok := lookupField(entityType, name)
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%q", name))
+			continue
+		}
+		selection = append(selection, field)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("no fields given")
+	}
+	return selection, nil
+}
+
+// lookupField finds the encoded field of the struct type with the given JSON or YAML name, ignoring case.
+func lookupField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(structType) {
+		jsonName, _, ok := model.JSONFieldName(field)
+		if !ok {
+			continue
+		}
+		yamlName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if strings.EqualFold(name, jsonName) || (yamlName != "" && strings.EqualFold(name, yamlName)) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// project returns each record as a map containing only the selected fields, keyed by their JSON
+// names. Empty fields tagged omitempty are left out, as they would be when encoding the record.
+func project[T any](records []T, selection fieldSelection) []map[string]any {
+	projected := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		value := reflect.ValueOf(record)
+		fields := make(map[string]any, len(selection))
+		for _, field := range selection {
+			name, omitEmpty, _ := model.JSONFieldName(field)
+			fieldValue := value.FieldByIndex(field.Index)
+			if omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			fields[name] = fieldValue.Interface()
+		}
+		projected = append(projected, fields)
+	}
+	return projected
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestParseFields(t *testing.T) {
+	selection, err := parseFields[model.Person]("name, github_handle,Email")
+	require.NoError(t, err)
+	var names []string
+	for _, field := range selection {
+		names = append(names, field.Name)
+	}
+	assert.Equal(t, []string{"Name", "GithubHandle", "Email"}, names)
+
+	_, err = parseFields[model.Person]("name,shoe_size,age")
+	assert.EqualError(t, err, `unknown fields: "shoe_size", "age"`)
+
+	_, err = parseFields[model.Person](",")
+	assert.EqualError(t, err, "no fields given")
+}
+
+func TestProjectOmitsEmptyFields(t *testing.T) {
+	selection, err := parseFields[model.Person]("name,email")
+	require.NoError(t, err)
+
+	people := []model.Person{{Name: "Alice Smith", Email: "alice@example.com"}, {Name: "Bob Jones"}}
+	assert.Equal(t, []map[string]any{
+		{"Name": "Alice Smith", "Email": "alice@example.com"},
+		{"Name": "Bob Jones"},
+	}, project(people, selection))
+}
+
+func TestGetPeopleWithFields(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?fields=name,role&team=Platform")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var people []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &people))
+	assert.Equal(t, []map[string]any{
+		{"Name": "Alice Smith", "Role": ""},
+		{"Name": "Bob Jones", "Role": ""},
+	}, people)
+}
+
+func TestGetPeopleWithFieldsAsNDJSON(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?fields=name&format=ndjson&limit=1")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "{\"Name\":\"Alice Smith\"}\n", response.Body.String())
+}
+
+func TestGetPeopleWithUnknownFields(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?fields=name,shoe_size")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), `unknown fields: \"shoe_size\"`)
+}
+
+func TestGetPeopleCSVWithFields(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people.csv?fields=name")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
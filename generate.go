@@ -3,11 +3,67 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
 	"github.com/rvodden/teams/internal/codegen"
 	"github.com/rvodden/teams/model"
 )
 
 func main() {
-	codegen.GenerateCodeFile("person", "people", model.Person{})
-	codegen.GenerateCodeFile("team", "teams", model.Team{})
+	sourceDir := flag.String("data", codegen.DefaultSourceDir, "directory containing the YAML source files")
+	typeScriptFile := flag.String("typescript", "", "path of a TypeScript .d.ts file to write the model interfaces to, if any")
+	flag.Parse()
+
+	people, peopleFiles, err := codegen.LoadEntities[model.Person](*sourceDir, "people")
+	if err != nil {
+		log.Fatalf("failed to load people: %v", err)
+	}
+	teams, teamFiles, err := codegen.LoadEntities[model.Team](*sourceDir, "teams")
+	if err != nil {
+		log.Fatalf("failed to load teams: %v", err)
+	}
+	peopleSource, teamsSource := codegen.DescribeSourceFiles(peopleFiles), codegen.DescribeSourceFiles(teamFiles)
+
+	if err := codegen.ValidateUniqueNames(people, peopleFiles); err != nil {
+		log.Fatalf("duplicate people:\n%v", err)
+	}
+	if err := codegen.ValidateUniqueIDs(people, peopleFiles); err != nil {
+		log.Fatalf("duplicate person IDs:\n%v", err)
+	}
+	if err := codegen.ValidateUniqueNames(teams, teamFiles); err != nil {
+		log.Fatalf("duplicate teams:\n%v", err)
+	}
+	if err := model.ValidatePeople(people); err != nil {
+		log.Fatalf("invalid people in %s:\n%v", peopleSource, err)
+	}
+	if err := codegen.ValidateTeams(teams); err != nil {
+		log.Fatalf("invalid teams in %s:\n%v", teamsSource, err)
+	}
+	if err := codegen.ValidateUniqueSlackChannels(teams); err != nil {
+		log.Fatalf("shared slack channels in %s:\n%v", teamsSource, err)
+	}
+	if err := codegen.ValidateHierarchy(teams); err != nil {
+		log.Fatalf("invalid team hierarchy in %s:\n%v", teamsSource, err)
+	}
+	if err := codegen.ValidateMembers(people, teams); err != nil {
+		log.Fatalf("invalid team members in %s:\n%v", teamsSource, err)
+	}
+
+	// Set TEAMS_REQUIRE_MEMBERSHIP=true to reject people who are not on any team.
+	if requireMembership, _ := strconv.ParseBool(os.Getenv("TEAMS_REQUIRE_MEMBERSHIP")); requireMembership {
+		if err := codegen.ValidateMembership(people, teams); err != nil {
+			log.Fatalf("invalid people in %s: %v", peopleSource, err)
+		}
+	}
+
+	codegen.GenerateCodeFile("person", "people", people)
+	codegen.GenerateCodeFile("team", "teams", teams)
+	codegen.GenerateIndexFile(teams, codegen.Options{})
+	codegen.GenerateVersionFile(append(peopleFiles, teamFiles...), codegen.Options{})
+	if *typeScriptFile != "" {
+		codegen.GenerateTypeScript(*typeScriptFile, model.Person{}, model.Team{})
+	}
 }
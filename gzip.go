@@ -0,0 +1,177 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinSize is the size in bytes below which responses are not compressed, as the saving is not
+// worth the overhead.
+const gzipMinSize = 1024
+
+// acceptsGzip reports whether the Accept-Encoding header permits a gzip-encoded response.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, parameters, _ := strings.Cut(coding, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		quality := strings.ReplaceAll(parameters, " ", "")
+		return quality != "q=0" && quality != "q=0.0" && quality != "q=0.00" && quality != "q=0.000"
+	}
+	return false
+}
+
+// gzipResponseWriter compresses a response with gzip once its body reaches a minimum size.
+//
+// The status and the start of the body are held back until either the body reaches minSize, at
+// which point the response is compressed, or the handler finishes or flushes. A response which
+// finishes below minSize is sent uncompressed; a response which is flushed is compressed, so that
+// streaming responses are compressed too.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+	status  int
+	buffer  []byte
+	decided bool
+	gzip    *gzip.Writer
+}
+
+// WriteHeader records the status, which is sent once it is known whether the response is compressed.
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow sends the response uncompressed if it has not yet been decided whether to compress it.
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.sendUncompressed()
+	}
+}
+
+// Status returns the response status.
+func (w *gzipResponseWriter) Status() int {
+	return w.status
+}
+
+// Write writes part of the response body.
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) >= w.minSize {
+			if err := w.sendCompressed(); err != nil {
+				return 0, err
+			}
+		}
+		return len(data), nil
+	}
+	if w.gzip != nil {
+		return w.gzip.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes part of the response body.
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends everything written so far to the client, compressing the response if it has not
+// yet been decided whether to.
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if err := w.sendCompressed(); err != nil {
+			return
+		}
+	}
+	if w.gzip != nil {
+		_ = w.gzip.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// sendCompressed sends the status with the headers for a gzip-encoded response, followed by the
+// buffered body. If the handler has already encoded the response itself, or the response has no
+// body, it is sent as it is instead.
+func (w *gzipResponseWriter) sendCompressed() error {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || !bodyAllowed(w.status) {
+		w.sendUncompressed()
+		return nil
+	}
+
+	w.decided = true
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	// The compressed representation is not byte-for-byte identical to the uncompressed one.
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.gzip = gzip.NewWriter(w.ResponseWriter)
+	_, err := w.gzip.Write(w.buffer)
+	w.buffer = nil
+	return err
+}
+
+// sendUncompressed sends the status followed by the buffered body, without compression.
+func (w *gzipResponseWriter) sendUncompressed() {
+	w.decided = true
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if len(w.buffer) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buffer)
+	}
+	w.buffer = nil
+}
+
+// finish sends whatever remains of the response once the handler has returned.
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		w.sendUncompressed()
+	}
+	if w.gzip != nil {
+		_ = w.gzip.Close()
+	}
+}
+
+// bodyAllowed reports whether a response with the given status may have a body.
+func bodyAllowed(status int) bool {
+	return !(status >= 100 && status < 200) && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// compress returns middleware which compresses responses with gzip for clients which accept it.
+//
+// Responses smaller than minSize bytes are sent uncompressed.
+func compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipResponseWriter{ResponseWriter: original, minSize: minSize, status: http.StatusOK}
+		c.Writer = writer
+		defer func() {
+			c.Writer = original
+			// If the handler panics, nothing is sent, so that recovery further up the chain can
+			// respond with an error rather than the status held back so far.
+			if err := recover(); err != nil {
+				panic(err)
+			}
+			writer.finish()
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+// manyPeople returns enough people for the /people response to exceed gzipMinSize.
+func manyPeople() []model.Person {
+	var people []model.Person
+	for i := 0; i < 50; i++ {
+		people = append(people, model.Person{Name: fmt.Sprintf("Person %d", i), Email: fmt.Sprintf("person%d@example.com", i)})
+	}
+	return people
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, acceptsGzip("gzip"))
+	assert.True(t, acceptsGzip("deflate, gzip;q=0.8"))
+	assert.False(t, acceptsGzip(""))
+	assert.False(t, acceptsGzip("deflate, br"))
+	assert.False(t, acceptsGzip("gzip;q=0"))
+}
+
+func TestCompression(t *testing.T) {
+	seedData(t, manyPeople(), testTeams)
+
+	uncompressed := performRequest(t, http.MethodGet, "/people")
+	require.Equal(t, http.StatusOK, uncompressed.Code)
+	assert.Empty(t, uncompressed.Header().Get("Content-Encoding"))
+	assert.Contains(t, uncompressed.Header().Values("Vary"), "Accept-Encoding")
+
+	request := httptest.NewRequest(http.MethodGet, "/people", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	compressed := serveRequest(t, request)
+	require.Equal(t, http.StatusOK, compressed.Code)
+	assert.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+	assert.Contains(t, compressed.Header().Values("Vary"), "Accept-Encoding")
+	assert.Equal(t, "W/"+uncompressed.Header().Get("ETag"), compressed.Header().Get("ETag"))
+	assert.Less(t, compressed.Body.Len(), uncompressed.Body.Len())
+
+	reader, err := gzip.NewReader(compressed.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, uncompressed.Body.String(), string(body))
+}
+
+func TestCompressionSkipsSmallResponses(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	request := httptest.NewRequest(http.MethodGet, "/teams/Platform", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	response := serveRequest(t, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, response.Header().Get("Content-Encoding"))
+	assert.Contains(t, response.Body.String(), `"Platform"`)
+}
+
+func TestCompressionOfConditionalRequest(t *testing.T) {
+	seedData(t, manyPeople(), testTeams)
+
+	request := httptest.NewRequest(http.MethodGet, "/people", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	first := serveRequest(t, request)
+
+	request = httptest.NewRequest(http.MethodGet, "/people", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	second := serveRequest(t, request)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Empty(t, second.Body.String())
+	assert.Empty(t, second.Header().Get("Content-Encoding"))
+}
+
+func TestCompressionKeepsOtherVaryHeaders(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_CORS_ORIGINS", "https://example.com")
+
+	request := httptest.NewRequest(http.MethodGet, "/teams", nil)
+	request.Header.Set("Origin", "https://example.com")
+	response := serveRequest(t, request)
+	assert.Contains(t, response.Header().Values("Vary"), "Origin")
+	assert.Contains(t, response.Header().Values("Vary"), "Accept-Encoding")
+}
+
+func TestCompressionOfPanickingHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard), compress(gzipMinSize))
+	router.GET("/panic", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		panic("handler failed")
+	})
+
+	for _, acceptEncoding := range []string{"", "gzip"} {
+		t.Run(acceptEncoding, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			request.Header.Set("Accept-Encoding", acceptEncoding)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+			assert.Equal(t, http.StatusInternalServerError, response.Code)
+			assert.Empty(t, response.Header().Get("Content-Encoding"))
+		})
+	}
+}
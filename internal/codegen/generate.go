@@ -1,37 +1,46 @@
 package codegen
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
 	"log"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 )
 
 // generateTemplate creates a Go code template string for a given entity type.
 //
 // It generates a template that can be used to create a slice of entities
-// in the generated_data package.
+// in the generated package.
 //
 // Parameters:
-//   - pluralName: A string representing the plural name of the entity type,
-//     used as the variable name for the slice in the generated code.
+//   - packageName: The name of the package the generated code belongs to.
+//   - variableName: The name of the variable holding the slice in the generated code.
 //   - entity: An interface{} representing an instance of the entity type,
 //     used to reflect on its structure and codegen appropriate field templates.
 //
 // Returns:
 //
 //	A string containing the generated Go code template, which includes:
-//	- A package declaration for generated_data
-//	- An import statement for the model package
+//	- A package declaration for the given package
+//	- An import statement for the package defining the entity type
 //	- A variable declaration for a slice of the entity type
 //	- A template for populating the slice with entity instances
-func generateTemplate(pluralName string, entity interface{}) string {
+func generateTemplate(packageName string, variableName string, entity interface{}) string {
 	entityType := reflect.TypeOf(entity)
 	var fields []string
 	for i := 0; i < entityType.NumField(); i++ {
@@ -43,22 +52,24 @@ func generateTemplate(pluralName string, entity interface{}) string {
 		switch fieldType.Kind() {
 		case reflect.Slice:
 			fieldTemplate = fmt.Sprintf(`%s: []%s{ {{- range .%s }}"{{ . }}",{{- end }} }`, fieldName, fieldType.Elem(), fieldName)
+		case reflect.Bool:
+			fieldTemplate = fmt.Sprintf(`%s: {{ .%s }}`, fieldName, fieldName)
 		default:
 			fieldTemplate = fmt.Sprintf(`%s: "{{ .%s }}"`, fieldName, fieldName)
 		}
 		fields = append(fields, fieldTemplate)
 	}
 
-	return fmt.Sprintf(`package generated_data
+	return fmt.Sprintf(`package %s
 
-import "github.com/rvodden/teams/model"
+import %q
 
 var %s = []%s{
 {{- range . }}
     {%s},
 {{- end }}
 }
-`, pluralName, entityType, strings.Join(fields, ", "))
+`, packageName, entityType.PkgPath(), variableName, entityType, strings.Join(fields, ", "))
 }
 
 // sanitizeEntity trims whitespace from string fields and string slice elements of the given entity.
@@ -110,10 +121,29 @@ func sanitizeEntity[entityType any](entity *entityType) error {
 	return nil
 }
 
+// sortEntitiesByName sorts entities in place by their Name field, so that the generated code does not
+// depend on the order in which the source data was read.
+//
+// The sort is stable and compares names byte by byte, so it gives the same result regardless of locale.
+// Entities without a string Name field are left in their original order.
+func sortEntitiesByName[entityType any](entities []entityType) {
+	nameField, ok := reflect.TypeFor[entityType]().FieldByName("Name")
+	if !ok || nameField.Type.Kind() != reflect.String {
+		return
+	}
+	name := func(entity *entityType) string {
+		return reflect.ValueOf(entity).Elem().FieldByIndex(nameField.Index).String()
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		return name(&entities[i]) < name(&entities[j])
+	})
+}
+
 // generateCodeString generates a code string from a slice of entities using a provided template and sanitizer function.
 //
-// This function takes a slice of entities, applies a sanitizer function to each entity,
-// and then executes a provided template with the sanitized entities to codegen a code string.
+// This function takes a slice of entities, applies a sanitizer function to each entity, sorts them
+// by name, and then executes a provided template with the sanitized entities to codegen a code string.
+// The entities are sanitized and sorted in place.
 //
 // Parameters:
 //   - entities: A slice of entities of type entityType to be processed and used in the template.
@@ -135,6 +165,7 @@ func generateCodeString[entityType any](entities []entityType, templateString st
 		}
 		slog.Info(fmt.Sprintf("sanitized entity %v", i), "entity", entities[i])
 	}
+	sortEntitiesByName(entities)
 
 	var sb strings.Builder
 	err := tmpl.Execute(&sb, entities)
@@ -144,40 +175,453 @@ func generateCodeString[entityType any](entities []entityType, templateString st
 	return sb.String(), nil
 }
 
-// GenerateCodeFile generates a Go code file containing a slice of entities based on YAML data.
+// DefaultSourceDir is the directory, relative to the working directory, from which the YAML source files are
+// read when no other directory is given.
+const DefaultSourceDir = "data"
+
+// SourceDataFile returns the path of the main YAML file from which entities with the given plural name are read.
+//
+// Parameters:
+//   - sourceDir: The directory containing the YAML source files. If empty, DefaultSourceDir is used.
+//   - pluralName: A string representing the plural name of the entity type.
+func SourceDataFile(sourceDir string, pluralName string) string {
+	if sourceDir == "" {
+		sourceDir = DefaultSourceDir
+	}
+	return filepath.Join(sourceDir, pluralName+".yaml")
+}
+
+// SourceDataFiles returns the paths of all the YAML files from which entities with the given plural name are read.
 //
-// This function reads entity data from a YAML file, unmarshals it into a slice of entities,
-// generates a Go code template, and writes the resulting code to a file in the generated_data package.
+// These are the main file given by SourceDataFile, if it exists, followed by any .yaml files in the
+// subdirectory of the source directory named after the entity type, in lexical order.
 //
 // Parameters:
-//   - name: A string representing the singular name of the entity type (currently unused in the function body).
-//   - pluralName: A string representing the plural name of the entity type, used for file naming and code codegen.
-//   - exampleEntity: An instance of the entity type, used as a template for code codegen.
+//   - sourceDir: The directory containing the YAML source files. If empty, DefaultSourceDir is used.
+//   - pluralName: A string representing the plural name of the entity type.
 //
-// The function does not return any values, but it will log fatal errors if any step in the process fails.
-func GenerateCodeFile[entityType any](name string, pluralName string, exampleEntity entityType) {
-	sourceDataFile := "data/" + pluralName + ".yaml"
+// Returns:
+//   - []string: The paths of the source files.
+//   - error: An error if there are no source files. Returns nil if successful.
+func SourceDataFiles(sourceDir string, pluralName string) ([]string, error) {
+	mainFile := SourceDataFile(sourceDir, pluralName)
+
+	var files []string
+	if _, err := os.Stat(mainFile); err == nil {
+		files = append(files, mainFile)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// Glob returns matches in lexical order.
+	subdirectoryFiles, err := filepath.Glob(filepath.Join(strings.TrimSuffix(mainFile, ".yaml"), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, subdirectoryFiles...)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no source files found for %s: %w", pluralName, fs.ErrNotExist)
+	}
+	return files, nil
+}
+
+// LoadEntities reads the YAML data files for an entity type and unmarshals them into a slice of entities.
+//
+// The files are parsed concurrently, by up to GOMAXPROCS workers, but the entities are returned in the
+// same order as if the files had been parsed one after another. Parsed files are not cached between
+// runs; each file is read exactly once per run, and unchanged output is not rewritten.
+//
+// Whitespace is trimmed from the entities as they are loaded, so that they are validated with the
+// same values as are generated.
+//
+// Parameters:
+//   - sourceDir: The directory containing the YAML source files. If empty, DefaultSourceDir is used.
+//   - pluralName: A string representing the plural name of the entity type, used to locate the source data files.
+//
+// Returns:
+//   - []entityType: The entities read from the source data files, in the order the files are returned by SourceDataFiles.
+//   - []string: The path of the file each entity was read from, so that the entity at index i was read from the file at index i.
+//   - error: An error if a file cannot be read or its contents cannot be unmarshalled. Returns nil if successful.
+func LoadEntities[entityType any](sourceDir string, pluralName string) ([]entityType, []string, error) {
+	sourceDataFiles, err := SourceDataFiles(sourceDir, pluralName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find files: %w", err)
+	}
+
+	return loadFiles[entityType](sourceDataFiles, runtime.GOMAXPROCS(0))
+}
+
+// loadFiles reads and unmarshals the given files using a pool of the given number of workers.
+//
+// Every file is attempted, even if some fail, and the errors from all the files which failed are
+// returned together, in the order of the files.
+//
+// Parameters:
+//   - files: The YAML files to read.
+//   - workers: The maximum number of files to parse at once.
+//
+// Returns:
+//   - []entityType: The entities from all of the files, in the order of the files.
+//   - []string: The file each entity was read from, in the same order as the entities.
+//   - error: An error describing every file which could not be read or unmarshalled. Returns nil if successful.
+func loadFiles[entityType any](files []string, workers int) ([]entityType, []string, error) {
+	type result struct {
+		entities []entityType
+		err      error
+	}
+	results := make([]result, len(files))
 
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i].entities, results[i].err = loadFile[entityType](files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var listOfEntities []entityType
+	var entityFiles []string
+	var errs []error
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		listOfEntities = append(listOfEntities, result.entities...)
+		for range result.entities {
+			entityFiles = append(entityFiles, files[i])
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	return listOfEntities, entityFiles, nil
+}
+
+// loadFile reads a single YAML data file and unmarshals it into a slice of sanitized entities.
+func loadFile[entityType any](sourceDataFile string) ([]entityType, error) {
 	data, err := os.ReadFile(sourceDataFile)
 	if err != nil {
-		log.Fatalf("failed to read file: %v", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	slog.Info("data:", "file", sourceDataFile, "data", data)
+
+	entities, err := decodeEntities[entityType](data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML:\n%w", locateYAMLError(sourceDataFile, err))
 	}
+	// Trim fields now rather than when generating, so that validation sees the values which are generated.
+	for i := range entities {
+		if err := sanitizeEntity(&entities[i]); err != nil {
+			return nil, err
+		}
+	}
+	slog.Info("entities:", "file", sourceDataFile, "entities", entities)
+
+	return entities, nil
+}
 
-	slog.Info("data:", "data", data)
+// ParseEntities unmarshals entities from YAML which was read from somewhere other than the source
+// directory, preparing them in the same way as entities which are generated into code.
+//
+// Parameters:
+//   - source: A description of where the YAML was read from, for use in error messages.
+//   - data: The YAML stream to decode.
+//
+// Returns:
+//   - []entityType: The entities, sanitized and sorted by name.
+//   - error: An error if the YAML cannot be unmarshalled. Returns nil if successful.
+func ParseEntities[entityType any](source string, data []byte) ([]entityType, error) {
+	entities, err := decodeEntities[entityType](data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML:\n%w", locateYAMLError(source, err))
+	}
+	for i := range entities {
+		if err := sanitizeEntity(&entities[i]); err != nil {
+			return nil, err
+		}
+	}
+	sortEntitiesByName(entities)
+	return entities, nil
+}
+
+// DescribeSourceFiles returns a description of the distinct files in the given list, for use in error messages.
+func DescribeSourceFiles(files []string) string {
+	var distinct []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			distinct = append(distinct, file)
+		}
+	}
+	return strings.Join(distinct, ", ")
+}
+
+// decodeEntities unmarshals entities from a stream of one or more YAML documents.
+//
+// Each document may contain either a sequence of entities or a single entity. Anchors and aliases are
+// resolved within each document, and empty documents are ignored. Decoding is strict: a key which does
+// not correspond to a field of the entity type is an error, as is a value of the wrong type.
+//
+// Parameters:
+//   - data: The YAML stream to decode.
+//
+// Returns:
+//   - []entityType: The entities from all the documents, in the order they appear in the stream.
+//   - error: An error if any document cannot be unmarshalled. Returns nil if successful.
+func decodeEntities[entityType any](data []byte) ([]entityType, error) {
+	// The documents are first decoded into nodes to find out what kind of content they hold, and then
+	// decoded again with a strict decoder which is kept in step with the first.
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	strictDecoder := yaml.NewDecoder(bytes.NewReader(data))
+	strictDecoder.KnownFields(true)
 
 	var listOfEntities []entityType
-	if err := yaml.Unmarshal(data, &listOfEntities); err != nil {
-		log.Fatalf("failed to unmarshal YAML: %v", err)
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var root *yaml.Node
+		if len(node.Content) > 0 {
+			root = node.Content[0]
+		}
+		switch {
+		case root != nil && root.Kind == yaml.SequenceNode:
+			var entities []entityType
+			if err := strictDecoder.Decode(&entities); err != nil {
+				return nil, err
+			}
+			for i := range entities {
+				if err := applyDefaults(&entities[i], root.Content[i]); err != nil {
+					return nil, err
+				}
+			}
+			listOfEntities = append(listOfEntities, entities...)
+		case root != nil && root.Kind == yaml.MappingNode:
+			var entity entityType
+			if err := strictDecoder.Decode(&entity); err != nil {
+				return nil, err
+			}
+			if err := applyDefaults(&entity, root); err != nil {
+				return nil, err
+			}
+			listOfEntities = append(listOfEntities, entity)
+		case root == nil || (root.Kind == yaml.ScalarNode && root.Tag == "!!null"):
+			var empty yaml.Node
+			if err := strictDecoder.Decode(&empty); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("line %d: expected a sequence or mapping", root.Line)
+		}
 	}
-	slog.Info("entities:", "entities", data)
 
-	templ := generateTemplate(cases.Title(language.BritishEnglish).String(pluralName), exampleEntity)
+	return listOfEntities, nil
+}
+
+// applyDefaults sets the fields of an entity which were not given in the YAML it was decoded from to
+// their default values.
+//
+// A field's default value is given, as YAML, by its default struct tag. Fields without the tag, and
+// fields whose key is present in the mapping node the entity was decoded from, are left unchanged.
+//
+// Parameters:
+//   - entity: A pointer to the entity decoded from the node.
+//   - node: The YAML mapping node the entity was decoded from.
+//
+// Returns:
+//   - An error if a default value cannot be unmarshalled into its field, or nil if successful.
+func applyDefaults[entityType any](entity *entityType, node *yaml.Node) error {
+	val := reflect.ValueOf(entity).Elem()
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		defaultValue, ok := field.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		if mappingHasKey(node, key) {
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(defaultValue), val.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid default value for field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// mappingHasKey reports whether a YAML mapping node contains the given key, either directly or
+// through a merge key. Aliases are followed.
+func mappingHasKey(node *yaml.Node, key string) bool {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			for valueNode.Kind == yaml.AliasNode {
+				valueNode = valueNode.Alias
+			}
+			merged := []*yaml.Node{valueNode}
+			if valueNode.Kind == yaml.SequenceNode {
+				merged = valueNode.Content
+			}
+			for _, mergedNode := range merged {
+				if mappingHasKey(mergedNode, key) {
+					return true
+				}
+			}
+		} else if keyNode.Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlLinePrefix matches the line number at the start of the messages in errors from the yaml package.
+var yamlLinePrefix = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// locateYAMLError rewrites an error from decoding a YAML file so that each of its messages begins with
+// the file name and, where the yaml package reports it, the line number, in the form file:line: message.
+func locateYAMLError(sourceDataFile string, err error) error {
+	messages := []string{err.Error()}
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		messages = typeError.Errors
+	}
+
+	located := make([]string, len(messages))
+	for i, message := range messages {
+		if match := yamlLinePrefix.FindStringSubmatch(message); match != nil {
+			located[i] = fmt.Sprintf("%s:%s: %s", sourceDataFile, match[1], message[len(match[0]):])
+		} else {
+			located[i] = fmt.Sprintf("%s: %s", sourceDataFile, message)
+		}
+	}
+	return errors.New(strings.Join(located, "\n"))
+}
+
+// DefaultPackage is the name of the package generated code belongs to when no other package is given.
+const DefaultPackage = "generated_data"
+
+// DefaultOutputDir is the directory, relative to the working directory, to which generated code is
+// written when no other directory is given.
+const DefaultOutputDir = "internal/generated_data"
+
+// Options control where generated code is written and what it is called. Fields which are left
+// empty take their default values.
+type Options struct {
+	// Package is the name of the package the generated code belongs to. It defaults to DefaultPackage.
+	Package string
+	// OutputDir is the directory the generated file is written to. It defaults to DefaultOutputDir.
+	OutputDir string
+	// VariableName is the name of the exported variable holding the entities. It defaults to the
+	// plural name of the entity type in title case.
+	VariableName string
+}
+
+// withDefaults returns a copy of the options with any empty fields set to their defaults for the
+// entity type with the given plural name.
+func (o Options) withDefaults(pluralName string) Options {
+	if o.Package == "" {
+		o.Package = DefaultPackage
+	}
+	if o.OutputDir == "" {
+		o.OutputDir = DefaultOutputDir
+	}
+	if o.VariableName == "" {
+		o.VariableName = cases.Title(language.BritishEnglish).String(pluralName)
+	}
+	return o
+}
+
+// GenerateCodeFile generates a Go code file containing a slice of entities.
+//
+// This function generates a Go code template for the entity type, executes it with the given entities,
+// and writes the resulting code to a file in the generated_data package. It is equivalent to
+// GenerateCodeFileWithOptions with the default options.
+//
+// Parameters:
+//   - name: A string representing the singular name of the entity type (currently unused in the function body).
+//   - pluralName: A string representing the plural name of the entity type, used for file naming and code codegen.
+//   - listOfEntities: The entities to be written to the generated file, typically obtained from LoadEntities.
+//
+// The function does not return any values, but it will log fatal errors if any step in the process fails.
+func GenerateCodeFile[entityType any](name string, pluralName string, listOfEntities []entityType) {
+	GenerateCodeFileWithOptions(name, pluralName, listOfEntities, Options{})
+}
+
+// GenerateCodeFileWithOptions generates a Go code file containing a slice of entities, in the package
+// and directory and with the variable name given by the options.
+//
+// Parameters:
+//   - name: A string representing the singular name of the entity type (currently unused in the function body).
+//   - pluralName: A string representing the plural name of the entity type, used for file naming and,
+//     unless the options say otherwise, the variable name.
+//   - listOfEntities: The entities to be written to the generated file, typically obtained from LoadEntities.
+//   - options: Where the generated file is written and what it is called.
+//
+// The function does not return any values, but it will log fatal errors if any step in the process fails.
+func GenerateCodeFileWithOptions[entityType any](name string, pluralName string, listOfEntities []entityType, options Options) {
+	options = options.withDefaults(pluralName)
+
+	var exampleEntity entityType
+	templ := generateTemplate(options.Package, options.VariableName, exampleEntity)
 	entityCode, err := generateCodeString(listOfEntities, templ, sanitizeEntity)
 	if err != nil {
 		log.Fatalf("failed to codegen listOfEntities code: %v", err)
 	}
 
-	destinationDataFile := "internal/generated_data/" + pluralName + "_data.go"
+	writeCodeFile(filepath.Join(options.OutputDir, pluralName+"_data.go"), entityCode)
+}
+
+// writeCodeFile writes generated code to the given destination file, replacing any existing content.
+//
+// If the file already contains exactly the given code it is left untouched, so that its modification
+// time does not change and unnecessary rebuilds are avoided.
+//
+// The function does not return any values, but it will log fatal errors if the file cannot be written.
+func writeCodeFile(destinationDataFile string, code string) {
+	existing, err := os.ReadFile(destinationDataFile)
+	if err == nil && bytes.Equal(existing, []byte(code)) {
+		slog.Info("file unchanged, not rewriting", "file", destinationDataFile)
+		return
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("failed to read existing output file: %v", err)
+	}
+
 	f, err := os.Create(destinationDataFile)
 	if err != nil {
 		log.Fatalf("failed to create output file: %v", err)
@@ -189,7 +633,7 @@ func GenerateCodeFile[entityType any](name string, pluralName string, exampleEnt
 		}
 	}(f)
 
-	_, err = f.WriteString(entityCode)
+	_, err = f.WriteString(code)
 	if err != nil {
 		log.Fatalf("failed to write code to file: %v", err)
 	}
@@ -1 +1,348 @@
 package codegen
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+// readFixture reads the named file from the testdata directory.
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+// generateTeamsCode generates the teams data code for the given teams.
+func generateTeamsCode(t *testing.T, teams []model.Team) string {
+	t.Helper()
+	code, err := generateCodeString(teams, generateTemplate(DefaultPackage, "Teams", model.Team{}), sanitizeEntity)
+	require.NoError(t, err)
+	return code
+}
+
+func TestDecodeEntitiesResolvesAliases(t *testing.T) {
+	teams, err := decodeEntities[model.Team](readFixture(t, "aliased_members.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Team{
+		{Name: "Platform", InternalSlackChannel: "C001", Members: []string{"Alice Smith", "Bob Jones"}, Active: true},
+		{Name: "Platform On-Call", InternalSlackChannel: "C002", Members: []string{"Alice Smith", "Bob Jones"}, Active: true},
+	}, teams)
+
+	code := generateTeamsCode(t, teams)
+	assert.Contains(t, code, `{Name: "Platform", InternalSlackChannel: "C001", Members: []string{"Alice Smith","Bob Jones", }`)
+	assert.Contains(t, code, `{Name: "Platform On-Call", InternalSlackChannel: "C002", Members: []string{"Alice Smith","Bob Jones", }`)
+}
+
+func TestDecodeEntitiesReadsMultipleDocuments(t *testing.T) {
+	teams, err := decodeEntities[model.Team](readFixture(t, "multiple_documents.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Team{
+		{Name: "Platform", InternalSlackChannel: "C001", Members: []string{"Alice Smith"}, Active: true},
+		{Name: "Data", InternalSlackChannel: "C002", Members: []string{"Bob Jones"}, Active: true},
+	}, teams)
+
+	code := generateTeamsCode(t, teams)
+	assert.Contains(t, code, `{Name: "Platform", InternalSlackChannel: "C001", Members: []string{"Alice Smith", }`)
+	assert.Contains(t, code, `{Name: "Data", InternalSlackChannel: "C002", Members: []string{"Bob Jones", }`)
+}
+
+func TestDecodeEntitiesReadsSingleDocument(t *testing.T) {
+	people, err := decodeEntities[model.Person]([]byte("- name: Alice Smith\n- name: Bob Jones\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Person{{Name: "Alice Smith"}, {Name: "Bob Jones"}}, people)
+}
+
+func TestDecodeEntitiesRejectsScalarDocument(t *testing.T) {
+	_, err := decodeEntities[model.Person]([]byte("- name: Alice Smith\n---\njust a string\n"))
+	assert.ErrorContains(t, err, "line 3")
+}
+
+func TestGenerateCodeFileFromSourceDirectory(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "people.yaml"), []byte(`
+- name: Alice Smith
+  email: alice@example.com
+- name: Bob Jones
+`), 0o644))
+
+	people, _, err := LoadEntities[model.Person](sourceDir, "people")
+	require.NoError(t, err)
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll("internal/generated_data", 0o755))
+	GenerateCodeFile("person", "people", people)
+
+	code, err := os.ReadFile("internal/generated_data/people_data.go")
+	require.NoError(t, err)
+	assert.Equal(t, `package generated_data
+
+import "github.com/rvodden/teams/model"
+
+var People = []model.Person{
+    {Name: "Alice Smith", ID: "", Nickname: "", Email: "alice@example.com", Role: "", GithubHandle: "", SlackChannel: ""},
+    {Name: "Bob Jones", ID: "", Nickname: "", Email: "", Role: "", GithubHandle: "", SlackChannel: ""},
+}
+`, string(code))
+}
+
+func TestGenerateCodeFileWithOptions(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "roster")
+	require.NoError(t, os.MkdirAll(outputDir, 0o755))
+	people := []model.Person{{Name: "Alice Smith"}}
+
+	teams := []model.Team{{Name: "Platform", Members: []string{"Alice Smith"}}}
+	source := filepath.Join(t.TempDir(), "people.yaml")
+	require.NoError(t, os.WriteFile(source, []byte("- name: Alice Smith\n"), 0o644))
+	options := Options{Package: "roster", OutputDir: outputDir, VariableName: "Staff"}
+
+	GenerateCodeFileWithOptions("person", "people", people, options)
+	GenerateIndexFile(teams, options)
+	GenerateVersionFile([]string{source}, options)
+
+	for _, name := range []string{"people_data.go", "index_data.go", "version_data.go"} {
+		code, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err, name)
+		file, err := parser.ParseFile(token.NewFileSet(), name, code, 0)
+		require.NoError(t, err, name)
+		assert.Equal(t, "roster", file.Name.Name, name)
+		assert.True(t, strings.HasPrefix(string(code), "package roster\n"), name)
+	}
+
+	code, err := os.ReadFile(filepath.Join(outputDir, "people_data.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(code), `import "github.com/rvodden/teams/model"`)
+	assert.Contains(t, string(code), "var Staff = []model.Person{\n    {Name: \"Alice Smith\", ")
+}
+
+func TestOptionsWithDefaults(t *testing.T) {
+	assert.Equal(t, Options{Package: DefaultPackage, OutputDir: DefaultOutputDir, VariableName: "People"}, Options{}.withDefaults("people"))
+	assert.Equal(t, Options{Package: "roster", OutputDir: DefaultOutputDir, VariableName: "Teams"}, Options{Package: "roster"}.withDefaults("teams"))
+}
+
+func TestSourceDataFile(t *testing.T) {
+	assert.Equal(t, filepath.Join("data", "teams.yaml"), SourceDataFile("", "teams"))
+	assert.Equal(t, filepath.Join("/srv/data", "teams.yaml"), SourceDataFile("/srv/data", "teams"))
+}
+
+func TestLoadEntitiesRejectsUnknownFields(t *testing.T) {
+	_, _, err := LoadEntities[model.Team]("testdata/unknown_field", "teams")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join("testdata/unknown_field", "teams.yaml")+":3:")
+	assert.Contains(t, err.Error(), "membrs")
+}
+
+func TestLoadEntitiesRejectsValuesOfTheWrongType(t *testing.T) {
+	_, _, err := LoadEntities[model.Team]("testdata/wrong_type", "teams")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join("testdata/wrong_type", "teams.yaml")+":3:")
+	assert.Contains(t, err.Error(), "[]string")
+}
+
+func TestGenerateCodeStringSortsByName(t *testing.T) {
+	first := readFixture(t, "scrambled/first.yaml")
+	second := readFixture(t, "scrambled/second.yaml")
+
+	var outputs []string
+	for _, files := range [][][]byte{{first, second}, {second, first}} {
+		var teams []model.Team
+		for _, file := range files {
+			decoded, err := decodeEntities[model.Team](file)
+			require.NoError(t, err)
+			teams = append(teams, decoded...)
+		}
+		outputs = append(outputs, generateTeamsCode(t, teams))
+
+		var names []string
+		for _, team := range teams {
+			names = append(names, team.Name)
+		}
+		assert.Equal(t, []string{"Data", "Data", "Platform", "data engineering", "Ápps"}, names)
+	}
+
+	// Teams with the same name keep the order in which their files were read.
+	assert.Contains(t, outputs[0], "{Name: \"Data\", InternalSlackChannel: \"\", Members: []string{\"Bob Jones\", }, ParentTeam: \"\", Active: true},\n"+
+		"    {Name: \"Data\", InternalSlackChannel: \"C002\"")
+	assert.Contains(t, outputs[1], "{Name: \"Data\", InternalSlackChannel: \"C002\", Members: []string{ }, ParentTeam: \"\", Active: true},\n"+
+		"    {Name: \"Data\", InternalSlackChannel: \"\"")
+}
+
+func TestDecodeEntitiesDefaultsTeamsToActive(t *testing.T) {
+	teams, err := decodeEntities[model.Team](readFixture(t, "active.yaml"))
+	require.NoError(t, err)
+
+	active := make(map[string]bool)
+	for _, team := range teams {
+		active[team.Name] = team.Active
+	}
+	assert.Equal(t, map[string]bool{
+		"Platform":       true,
+		"Legacy":         false,
+		"Revived":        true,
+		"Disbanded":      false,
+		"Also Disbanded": false,
+	}, active)
+
+	single, err := decodeEntities[model.Team]([]byte("name: Platform\n"))
+	require.NoError(t, err)
+	assert.True(t, single[0].Active)
+
+	code := generateTeamsCode(t, teams)
+	assert.Contains(t, code, `{Name: "Legacy", InternalSlackChannel: "", Members: []string{ }, ParentTeam: "", Active: false},`)
+	assert.Contains(t, code, `{Name: "Platform", InternalSlackChannel: "", Members: []string{ }, ParentTeam: "", Active: true},`)
+}
+
+func TestLoadEntitiesReadsSubdirectory(t *testing.T) {
+	teams, files, err := LoadEntities[model.Team]("testdata/duplicates", "teams")
+	require.NoError(t, err)
+
+	var names []string
+	for _, team := range teams {
+		names = append(names, team.Name)
+	}
+	assert.Equal(t, []string{"Platform", "Data", "Security", "Platform"}, names)
+	assert.Equal(t, []string{
+		filepath.Join("testdata/duplicates", "teams.yaml"),
+		filepath.Join("testdata/duplicates", "teams.yaml"),
+		filepath.Join("testdata/duplicates/teams", "more.yaml"),
+		filepath.Join("testdata/duplicates/teams", "more.yaml"),
+	}, files)
+}
+
+func TestLoadEntitiesTrimsWhitespace(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "people.yaml"), []byte("- name: 'Alice Smith '\n  email: ' alice@example.com'\n- name: Alice Smith\n"), 0o644))
+
+	people, _, err := LoadEntities[model.Person](dir, "people")
+	require.NoError(t, err)
+	assert.Equal(t, []model.Person{{Name: "Alice Smith", Email: "alice@example.com"}, {Name: "Alice Smith"}}, people)
+}
+
+func TestLoadEntitiesWithoutSourceFiles(t *testing.T) {
+	_, _, err := LoadEntities[model.Team](t.TempDir(), "teams")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestWriteCodeFileSkipsUnchangedFiles(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "people_data.go")
+	writeCodeFile(destination, "package generated_data\n")
+
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(destination, past, past))
+
+	writeCodeFile(destination, "package generated_data\n")
+	info, err := os.Stat(destination)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(past), "unchanged file was rewritten")
+
+	writeCodeFile(destination, "package generated_data\n\nvar People = nil\n")
+	info, err = os.Stat(destination)
+	require.NoError(t, err)
+	assert.False(t, info.ModTime().Equal(past), "changed file was not rewritten")
+
+	code, err := os.ReadFile(destination)
+	require.NoError(t, err)
+	assert.Equal(t, "package generated_data\n\nvar People = nil\n", string(code))
+}
+
+func TestParseEntitiesSanitizesAndSorts(t *testing.T) {
+	teams, err := ParseEntities[model.Team]("remote", []byte("- name: ' Platform '\n  members: [' Bob Jones']\n- name: Data\n  active: false\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Team{
+		{Name: "Data"},
+		{Name: "Platform", Members: []string{"Bob Jones"}, Active: true},
+	}, teams)
+}
+
+func TestParseEntitiesLocatesErrors(t *testing.T) {
+	_, err := ParseEntities[model.Team]("https://example.com/teams.yaml", []byte("- name: Platform\n  membrs: []\n"))
+	assert.ErrorContains(t, err, "https://example.com/teams.yaml:2:")
+}
+
+// writeTeamFixtures writes the given number of team source files, each containing a few teams, to the
+// teams subdirectory of the given source directory, and returns the paths of the files in order.
+func writeTeamFixtures(tb testing.TB, sourceDir string, count int) []string {
+	tb.Helper()
+	require.NoError(tb, os.MkdirAll(filepath.Join(sourceDir, "teams"), 0o755))
+
+	var files []string
+	for i := 0; i < count; i++ {
+		file := filepath.Join(sourceDir, "teams", fmt.Sprintf("%04d.yaml", i))
+		data := fmt.Sprintf("- name: Team %d\n  members: [Person %d, Person %d]\n- name: Squad %d\n  active: false\n", count-i, i, i+1, i)
+		require.NoError(tb, os.WriteFile(file, []byte(data), 0o644))
+		files = append(files, file)
+	}
+	return files
+}
+
+// discardLogs silences the logging of each file's contents for the rest of the test.
+func discardLogs(tb testing.TB) {
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	tb.Cleanup(func() { slog.SetDefault(original) })
+}
+
+func TestLoadFilesIsDeterministicForAnyNumberOfWorkers(t *testing.T) {
+	discardLogs(t)
+	files := writeTeamFixtures(t, t.TempDir(), 200)
+
+	sequential, sequentialFiles, err := loadFiles[model.Team](files, 1)
+	require.NoError(t, err)
+	require.Len(t, sequential, 400)
+	assert.Equal(t, model.Team{Name: "Team 200", Members: []string{"Person 0", "Person 1"}, Active: true}, sequential[0])
+	assert.Equal(t, files[0], sequentialFiles[1])
+	expected := generateTeamsCode(t, sequential)
+
+	for _, workers := range []int{0, 2, 8, 1000} {
+		for attempt := 0; attempt < 5; attempt++ {
+			teams, teamFiles, err := loadFiles[model.Team](files, workers)
+			require.NoError(t, err)
+			assert.Equal(t, sequentialFiles, teamFiles)
+			assert.Equal(t, expected, generateTeamsCode(t, teams), "workers: %d", workers)
+		}
+	}
+}
+
+func TestLoadFilesReportsEveryError(t *testing.T) {
+	discardLogs(t)
+	sourceDir := t.TempDir()
+	files := writeTeamFixtures(t, sourceDir, 50)
+	require.NoError(t, os.WriteFile(files[10], []byte("- name: Broken\n  membrs: []\n"), 0o644))
+	require.NoError(t, os.WriteFile(files[40], []byte("- name: [Broken]\n"), 0o644))
+	files = append(files, filepath.Join(sourceDir, "missing.yaml"))
+
+	_, _, err := loadFiles[model.Team](files, 4)
+	require.Error(t, err)
+	message := err.Error()
+	assert.Contains(t, message, files[10]+":2:")
+	assert.Contains(t, message, files[40]+":1:")
+	assert.Contains(t, message, "failed to read file")
+	assert.Less(t, strings.Index(message, files[10]), strings.Index(message, files[40]))
+}
+
+func BenchmarkLoadEntities(b *testing.B) {
+	discardLogs(b)
+	sourceDir := b.TempDir()
+	writeTeamFixtures(b, sourceDir, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := LoadEntities[model.Team](sourceDir, "teams"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package codegen
+
+import (
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/rvodden/teams/model"
+)
+
+// indexTemplate is the template for the generated file containing the indexes derived from the teams.
+const indexTemplate = `package {{ .Package }}
+
+var TeamsByPerson = {{ template "index" .TeamsByPerson }}
+
+var SubTeams = {{ template "index" .SubTeams }}
+
+var TeamSizes = map[string]int{
+{{- range .TeamSizes }}
+    {{ printf "%q" .Name }}: {{ .Members }},
+{{- end }}
+}
+{{ define "index" }}map[string][]string{
+{{- range . }}
+    {{ printf "%q" .Key }}: { {{- range .Values }}{{ printf "%q" . }}, {{ end -}} },
+{{- end }}
+}{{ end }}`
+
+// indexEntry is a single key of a generated map along with its values.
+type indexEntry struct {
+	Key    string
+	Values []string
+}
+
+// index accumulates a mapping from keys to sets of values, from which index entries can be built.
+type index map[string]map[string]bool
+
+// add records that the given value belongs to the given key.
+func (i index) add(key string, value string) {
+	if i[key] == nil {
+		i[key] = make(map[string]bool)
+	}
+	i[key][value] = true
+}
+
+// entries returns the entries of the index, sorted by key, with the values for each key sorted, so
+// that the generated code is deterministic.
+func (i index) entries() []indexEntry {
+	entries := make([]indexEntry, 0, len(i))
+	for key, values := range i {
+		entry := indexEntry{Key: key}
+		for value := range values {
+			entry.Values = append(entry.Values, value)
+		}
+		sort.Strings(entry.Values)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Key < entries[b].Key })
+	return entries
+}
+
+// values returns the index as a map from each key to its sorted values, as found in the generated code.
+func (i index) values() map[string][]string {
+	values := make(map[string][]string, len(i))
+	for _, entry := range i.entries() {
+		values[entry.Key] = entry.Values
+	}
+	return values
+}
+
+// buildTeamsByPerson computes the names of the teams each member belongs to.
+//
+// A member listed more than once in a team is only counted once.
+func buildTeamsByPerson(teams []model.Team) index {
+	teamsByPerson := make(index)
+	for _, team := range teams {
+		for _, member := range team.Members {
+			teamsByPerson.add(member, team.Name)
+		}
+	}
+	return teamsByPerson
+}
+
+// buildSubTeams computes the names of the teams whose parent is each team.
+//
+// Teams without a parent are not included as values, and teams without children are not included as keys.
+func buildSubTeams(teams []model.Team) index {
+	subTeams := make(index)
+	for _, team := range teams {
+		if team.ParentTeam != "" {
+			subTeams.add(team.ParentTeam, team.Name)
+		}
+	}
+	return subTeams
+}
+
+// teamSizeEntry is the number of members of a single team, as emitted in the generated TeamSizes map.
+type teamSizeEntry struct {
+	Name    string
+	Members int
+}
+
+// buildTeamSizes computes the number of members of each team, sorted by team name so that the
+// generated code is deterministic.
+//
+// Members are counted as they are listed, in the same way as the statistics served by the API.
+func buildTeamSizes(teams []model.Team) []teamSizeEntry {
+	sizes := make([]teamSizeEntry, 0, len(teams))
+	for _, team := range teams {
+		sizes = append(sizes, teamSizeEntry{Name: team.Name, Members: len(team.Members)})
+	}
+	sort.SliceStable(sizes, func(a, b int) bool { return sizes[a].Name < sizes[b].Name })
+	return sizes
+}
+
+// generateIndexCodeString generates the code for the indexes derived from the given teams, in the
+// given package.
+//
+// Returns:
+//   - string: The generated code string.
+//   - error: An error if the template execution fails. Returns nil if successful.
+func generateIndexCodeString(packageName string, teams []model.Team) (string, error) {
+	tmpl := template.Must(template.New("indexes").Parse(indexTemplate))
+
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, struct {
+		Package       string
+		TeamsByPerson []indexEntry
+		SubTeams      []indexEntry
+		TeamSizes     []teamSizeEntry
+	}{packageName, buildTeamsByPerson(teams).entries(), buildSubTeams(teams).entries(), buildTeamSizes(teams)})
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// GenerateIndexFile generates a Go code file containing indexes derived from the teams.
+//
+// These are TeamsByPerson, a map from each team member to the sorted names of the teams they belong
+// to, SubTeams, a map from each parent team to the sorted names of its child teams, and TeamSizes, a
+// map from each team to its number of members.
+//
+// Parameters:
+//   - teams: The teams to derive the indexes from, typically obtained from LoadEntities.
+//   - options: The package and directory of the generated file. The variable name is not used.
+//
+// The function does not return any values, but it will log fatal errors if any step in the process fails.
+func GenerateIndexFile(teams []model.Team, options Options) {
+	options = options.withDefaults("")
+	indexCode, err := generateIndexCodeString(options.Package, teams)
+	if err != nil {
+		log.Fatalf("failed to codegen index code: %v", err)
+	}
+
+	writeCodeFile(filepath.Join(options.OutputDir, "index_data.go"), indexCode)
+}
+
+// BuildIndexes computes the same indexes as GenerateIndexFile, for teams which are loaded at run time
+// rather than generated into code.
+//
+// Returns:
+//   - teamsByPerson: A map from each team member to the sorted names of the teams they belong to.
+//   - subTeams: A map from each parent team to the sorted names of its child teams.
+//   - teamSizes: A map from each team to its number of members.
+func BuildIndexes(teams []model.Team) (teamsByPerson map[string][]string, subTeams map[string][]string, teamSizes map[string]int) {
+	teamSizes = make(map[string]int, len(teams))
+	for _, size := range buildTeamSizes(teams) {
+		teamSizes[size.Name] = size.Members
+	}
+	return buildTeamsByPerson(teams).values(), buildSubTeams(teams).values(), teamSizes
+}
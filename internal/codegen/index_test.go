@@ -0,0 +1,82 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestGenerateIndexCodeString(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Bob Jones", "Alice Smith"}},
+		{Name: "Data", Members: []string{"Alice Smith", "Alice Smith"}},
+	}
+
+	code, err := generateIndexCodeString(DefaultPackage, teams)
+	require.NoError(t, err)
+	assert.Equal(t, `package generated_data
+
+var TeamsByPerson = map[string][]string{
+    "Alice Smith": {"Data", "Platform", },
+    "Bob Jones": {"Platform", },
+}
+
+var SubTeams = map[string][]string{
+}
+
+var TeamSizes = map[string]int{
+    "Data": 2,
+    "Platform": 2,
+}
+`, code)
+}
+
+func TestBuildTeamsByPersonOmitsPeopleOnNoTeam(t *testing.T) {
+	teams := []model.Team{{Name: "Empty"}}
+
+	assert.Empty(t, buildTeamsByPerson(teams))
+}
+
+func TestBuildSubTeams(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform"},
+		{Name: "Storage", ParentTeam: "Platform"},
+		{Name: "Networking", ParentTeam: "Platform"},
+		{Name: "Block Storage", ParentTeam: "Storage"},
+	}
+
+	assert.Equal(t, []indexEntry{
+		{Key: "Platform", Values: []string{"Networking", "Storage"}},
+		{Key: "Storage", Values: []string{"Block Storage"}},
+	}, buildSubTeams(teams).entries())
+}
+
+func TestBuildIndexes(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice", "Bob"}},
+		{Name: "Storage", Members: []string{"Alice"}, ParentTeam: "Platform"},
+	}
+
+	teamsByPerson, subTeams, teamSizes := BuildIndexes(teams)
+	assert.Equal(t, map[string][]string{"Alice": {"Platform", "Storage"}, "Bob": {"Platform"}}, teamsByPerson)
+	assert.Equal(t, map[string][]string{"Platform": {"Storage"}}, subTeams)
+	assert.Equal(t, map[string]int{"Platform": 2, "Storage": 1}, teamSizes)
+}
+
+func TestGenerateIndexCodeStringEmitsTeamSizes(t *testing.T) {
+	teams, err := decodeEntities[model.Team](readFixture(t, "multiple_documents.yaml"))
+	require.NoError(t, err)
+	teams = append(teams, model.Team{Name: "Empty"})
+
+	code, err := generateIndexCodeString(DefaultPackage, teams)
+	require.NoError(t, err)
+	assert.Contains(t, code, `var TeamSizes = map[string]int{
+    "Data": 1,
+    "Empty": 0,
+    "Platform": 1,
+}
+`)
+}
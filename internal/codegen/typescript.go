@@ -0,0 +1,91 @@
+package codegen
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/rvodden/teams/model"
+)
+
+// typeScriptType returns the TypeScript type corresponding to the JSON encoding of the given Go type.
+func typeScriptType(goType reflect.Type) (string, error) {
+	switch goType.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.Slice, reflect.Array:
+		elementType, err := typeScriptType(goType.Elem())
+		if err != nil {
+			return "", err
+		}
+		return elementType + "[]", nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", goType)
+	}
+}
+
+// generateTypeScriptString generates TypeScript interface declarations describing the JSON encoding
+// of the given entities' types.
+//
+// Parameters:
+//   - entities: An instance of each entity type to declare, in the order the declarations should appear.
+//
+// Returns:
+//   - string: The generated declarations, one interface per entity type, named after the Go type.
+//   - error: An error if an entity is not a struct, or has a field whose type cannot be declared.
+//     Returns nil if successful.
+func generateTypeScriptString(entities ...interface{}) (string, error) {
+	var sb strings.Builder
+	for i, entity := range entities {
+		entityType := reflect.TypeOf(entity)
+		if entityType.Kind() != reflect.Struct {
+			return "", fmt.Errorf("%s is not a struct", entityType)
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		fmt.Fprintf(&sb, "export interface %s {\n", entityType.Name())
+		for _, field := range reflect.VisibleFields(entityType) {
+			name, optional, ok := model.JSONFieldName(field)
+			if !ok {
+				continue
+			}
+			fieldType, err := typeScriptType(field.Type)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", entityType.Name(), field.Name, err)
+			}
+			marker := ""
+			if optional {
+				marker = "?"
+			}
+			fmt.Fprintf(&sb, "    %s%s: %s;\n", name, marker, fieldType)
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String(), nil
+}
+
+// GenerateTypeScript generates a TypeScript declaration file containing an interface for each of the
+// given entities' types, so that clients of the API can share the shapes of its responses.
+//
+// Parameters:
+//   - destinationFile: The path of the .d.ts file to write.
+//   - entities: An instance of each entity type to declare, for example model.Person{}.
+//
+// The function does not return any values, but it will log fatal errors if any step in the process fails.
+func GenerateTypeScript(destinationFile string, entities ...interface{}) {
+	declarations, err := generateTypeScriptString(entities...)
+	if err != nil {
+		log.Fatalf("failed to codegen TypeScript declarations: %v", err)
+	}
+
+	writeCodeFile(destinationFile, declarations)
+}
@@ -0,0 +1,51 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestGenerateTypeScriptStringForTeam(t *testing.T) {
+	declarations, err := generateTypeScriptString(model.Team{})
+	require.NoError(t, err)
+	assert.Equal(t, `export interface Team {
+    Name: string;
+    InternalSlackChannel: string;
+    Members: string[];
+    ParentTeam: string;
+    Active: boolean;
+}
+`, declarations)
+}
+
+func TestGenerateTypeScriptStringMarksOmitEmptyFieldsOptional(t *testing.T) {
+	declarations, err := generateTypeScriptString(model.Person{}, model.Team{})
+	require.NoError(t, err)
+	assert.Contains(t, declarations, "export interface Person {\n    Name: string;\n")
+	assert.Contains(t, declarations, "    Email?: string;\n")
+	assert.Contains(t, declarations, "}\n\nexport interface Team {\n")
+}
+
+func TestGenerateTypeScriptStringRejectsUnsupportedTypes(t *testing.T) {
+	_, err := generateTypeScriptString(struct{ Lookup map[string]string }{})
+	assert.ErrorContains(t, err, "Lookup: unsupported type map[string]string")
+
+	_, err = generateTypeScriptString("not a struct")
+	assert.ErrorContains(t, err, "not a struct")
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "teams.d.ts")
+
+	GenerateTypeScript(destination, model.Team{})
+
+	data, err := os.ReadFile(destination)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "export interface Team {")
+}
@@ -0,0 +1,258 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rvodden/teams/model"
+)
+
+// ValidateMembers checks that every member of every team refers to a known person.
+//
+// Members are matched exactly against the IDs of the given people first, and then against their
+// names. A name shared by several people is ambiguous, and those people must be referred to by ID.
+// Each team with one or more unknown or ambiguous members contributes an error naming the team and
+// listing the offending member strings.
+//
+// Parameters:
+//   - people: The people that team members may refer to.
+//   - teams: The teams whose members should be checked.
+//
+// Returns:
+//   - An error describing all unknown and ambiguous members, or nil if every member refers to exactly one person.
+func ValidateMembers(people []model.Person, teams []model.Team) error {
+	ids := make(map[string]bool, len(people))
+	names := make(map[string]int, len(people))
+	for _, person := range people {
+		if person.ID != "" {
+			ids[person.ID] = true
+		}
+		names[person.Name]++
+	}
+
+	var errs []error
+	for _, team := range teams {
+		var unknown, ambiguous []string
+		for _, member := range team.Members {
+			switch {
+			case ids[member] || names[member] == 1:
+			case names[member] > 1:
+				ambiguous = append(ambiguous, fmt.Sprintf("%q", member))
+			default:
+				unknown = append(unknown, fmt.Sprintf("%q", member))
+			}
+		}
+		if len(unknown) > 0 {
+			errs = append(errs, fmt.Errorf("team %q has unknown members: %s", team.Name, strings.Join(unknown, ", ")))
+		}
+		if len(ambiguous) > 0 {
+			errs = append(errs, fmt.Errorf("team %q has members shared by more than one person, which must be referred to by ID: %s", team.Name, strings.Join(ambiguous, ", ")))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateTeams checks the fields of each team for values which are not in the expected format.
+//
+// Currently this checks that each team's internal Slack channel is a valid channel reference.
+//
+// Parameters:
+//   - teams: The teams to be checked.
+//
+// Returns:
+//   - An error naming each team with an invalid value, or nil if every team is valid.
+func ValidateTeams(teams []model.Team) error {
+	var errs []error
+	for _, team := range teams {
+		if err := model.ValidateSlackChannel(team.InternalSlackChannel); err != nil {
+			errs = append(errs, fmt.Errorf("team %q: %w", team.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateUniqueSlackChannels checks that no two teams share an internal Slack channel.
+//
+// Channels are compared exactly. Teams without an internal Slack channel are not checked. Each shared
+// channel contributes an error listing the teams which use it.
+//
+// Parameters:
+//   - teams: The teams to be checked.
+//
+// Returns:
+//   - An error describing every shared channel, or nil if each channel is used by at most one team.
+func ValidateUniqueSlackChannels(teams []model.Team) error {
+	var channels []string
+	teamsByChannel := make(map[string][]string)
+	for _, team := range teams {
+		if team.InternalSlackChannel == "" {
+			continue
+		}
+		if _, seen := teamsByChannel[team.InternalSlackChannel]; !seen {
+			channels = append(channels, team.InternalSlackChannel)
+		}
+		teamsByChannel[team.InternalSlackChannel] = append(teamsByChannel[team.InternalSlackChannel], fmt.Sprintf("%q", team.Name))
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		if sharing := teamsByChannel[channel]; len(sharing) > 1 {
+			errs = append(errs, fmt.Errorf("slack channel %q is used by more than one team: %s", channel, strings.Join(sharing, ", ")))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateMembership checks that every person is a member of at least one team, by ID or by name.
+//
+// Parameters:
+//   - people: The people who should each belong to a team.
+//   - teams: The teams whose members are considered.
+//
+// Returns:
+//   - An error listing the people who are not members of any team, or nil if there are none.
+func ValidateMembership(people []model.Person, teams []model.Team) error {
+	members := make(map[string]bool)
+	for _, team := range teams {
+		for _, member := range team.Members {
+			members[member] = true
+		}
+	}
+
+	var orphans []string
+	for _, person := range people {
+		if members[person.Name] || (person.ID != "" && members[person.ID]) {
+			continue
+		}
+		if person.ID != "" {
+			orphans = append(orphans, fmt.Sprintf("%q (id %q)", person.Name, person.ID))
+		} else {
+			orphans = append(orphans, fmt.Sprintf("%q", person.Name))
+		}
+	}
+	if len(orphans) > 0 {
+		return fmt.Errorf("people not on any team: %s", strings.Join(orphans, ", "))
+	}
+
+	return nil
+}
+
+// ValidateHierarchy checks that the parent of every team is a known team, and that no team is
+// transitively its own parent.
+//
+// Parameters:
+//   - teams: The teams whose hierarchy should be checked.
+//
+// Returns:
+//   - An error naming each unknown parent and describing each cycle, or nil if the hierarchy is valid.
+func ValidateHierarchy(teams []model.Team) error {
+	parents := make(map[string]string, len(teams))
+	for _, team := range teams {
+		parents[team.Name] = team.ParentTeam
+	}
+
+	var errs []error
+	for _, team := range teams {
+		if _, ok := parents[team.ParentTeam]; team.ParentTeam != "" && !ok {
+			errs = append(errs, fmt.Errorf("team %q has unknown parent team %q", team.Name, team.ParentTeam))
+		}
+	}
+
+	// Follow the chain of parents from each team in turn. A chain which reaches a team already
+	// explored from an earlier starting point cannot contain a new cycle.
+	explored := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		var chain []string
+		positions := make(map[string]int)
+		for current := team.Name; current != "" && !explored[current]; current = parents[current] {
+			if position, seen := positions[current]; seen {
+				cycle := append(chain[position:], current)
+				errs = append(errs, fmt.Errorf("team hierarchy contains a cycle: %s", strings.Join(cycle, " -> ")))
+				break
+			}
+			positions[current] = len(chain)
+			chain = append(chain, current)
+		}
+		for _, name := range chain {
+			explored[name] = true
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateUniqueNames checks that no two entities have the same name.
+//
+// Names are compared exactly, using each entity's Name field. Entities with a non-empty ID field are
+// distinguished by their ID instead, and are not checked; see ValidateUniqueIDs. Each duplicated name
+// contributes an error listing the files in which it is defined.
+//
+// Parameters:
+//   - entities: The entities to be checked.
+//   - files: The file each entity was read from, as returned by LoadEntities.
+//
+// Returns:
+//   - An error describing every duplicated name, or nil if the names are unique.
+func ValidateUniqueNames[entityType any](entities []entityType, files []string) error {
+	var names []string
+	filesByName := make(map[string][]string)
+	for i := range entities {
+		value := reflect.ValueOf(entities[i])
+		if id := value.FieldByName("ID"); id.IsValid() && id.String() != "" {
+			continue
+		}
+		name := value.FieldByName("Name").String()
+		if _, seen := filesByName[name]; !seen {
+			names = append(names, name)
+		}
+		filesByName[name] = append(filesByName[name], files[i])
+	}
+
+	var errs []error
+	for _, name := range names {
+		if definitions := filesByName[name]; len(definitions) > 1 {
+			errs = append(errs, fmt.Errorf("name %q is defined %d times, in %s", name, len(definitions), DescribeSourceFiles(definitions)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateUniqueIDs checks that no two people have the same ID.
+//
+// IDs are compared exactly. People without an ID are not checked. Each duplicated ID contributes an
+// error listing the files in which it is defined.
+//
+// Parameters:
+//   - people: The people to be checked.
+//   - files: The file each person was read from, as returned by LoadEntities.
+//
+// Returns:
+//   - An error describing every duplicated ID, or nil if the IDs are unique.
+func ValidateUniqueIDs(people []model.Person, files []string) error {
+	var ids []string
+	filesByID := make(map[string][]string)
+	for i, person := range people {
+		if person.ID == "" {
+			continue
+		}
+		if _, seen := filesByID[person.ID]; !seen {
+			ids = append(ids, person.ID)
+		}
+		filesByID[person.ID] = append(filesByID[person.ID], files[i])
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if definitions := filesByID[id]; len(definitions) > 1 {
+			errs = append(errs, fmt.Errorf("id %q is used %d times, in %s", id, len(definitions), DescribeSourceFiles(definitions)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,207 @@
+package codegen
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestValidateMembers(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}, {Name: "Bob Jones"}}
+	teams := []model.Team{{Name: "Platform", Members: []string{"Alice Smith", "Bob Jones"}}}
+
+	assert.NoError(t, ValidateMembers(people, teams))
+}
+
+func TestValidateMembersReportsUnknownMembers(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}}
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice Smith", "Alice Smiht", "Bob"}},
+		{Name: "Data", Members: []string{"Alice Smith"}},
+	}
+
+	err := ValidateMembers(people, teams)
+	require.Error(t, err)
+	assert.Equal(t, `team "Platform" has unknown members: "Alice Smiht", "Bob"`, err.Error())
+}
+
+func TestValidateTeams(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", InternalSlackChannel: "#platform"},
+		{Name: "Data"},
+	}
+
+	assert.NoError(t, ValidateTeams(teams))
+}
+
+func TestValidateTeamsReportsInvalidSlackChannels(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", InternalSlackChannel: "#platform"},
+		{Name: "Data", InternalSlackChannel: "general"},
+	}
+
+	err := ValidateTeams(teams)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `team "Data"`)
+	assert.Contains(t, err.Error(), `"general"`)
+	assert.NotContains(t, err.Error(), "Platform")
+}
+
+func TestValidateUniqueSlackChannels(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", InternalSlackChannel: "#platform"},
+		{Name: "Data"},
+		{Name: "Search"},
+	}
+
+	assert.NoError(t, ValidateUniqueSlackChannels(teams))
+}
+
+func TestValidateUniqueSlackChannelsReportsSharedChannels(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", InternalSlackChannel: "#platform"},
+		{Name: "Data", InternalSlackChannel: "#data"},
+		{Name: "Platform On-Call", InternalSlackChannel: "#platform"},
+	}
+
+	err := ValidateUniqueSlackChannels(teams)
+	assert.EqualError(t, err, `slack channel "#platform" is used by more than one team: "Platform", "Platform On-Call"`)
+}
+
+func TestValidateMembership(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}, {Name: "Bob Jones"}}
+	teams := []model.Team{{Name: "Platform", Members: []string{"Alice Smith"}}}
+
+	err := ValidateMembership(people, teams)
+	require.Error(t, err)
+	assert.Equal(t, `people not on any team: "Bob Jones"`, err.Error())
+
+	teams = append(teams, model.Team{Name: "Data", Members: []string{"Bob Jones"}})
+	assert.NoError(t, ValidateMembership(people, teams))
+}
+
+func TestValidateHierarchy(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform"},
+		{Name: "Storage", ParentTeam: "Platform"},
+		{Name: "Block Storage", ParentTeam: "Storage"},
+	}
+
+	assert.NoError(t, ValidateHierarchy(teams))
+}
+
+func TestValidateHierarchyReportsCycles(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", ParentTeam: "Networking"},
+		{Name: "Storage", ParentTeam: "Platform"},
+		{Name: "Networking", ParentTeam: "Storage"},
+		{Name: "Edge", ParentTeam: "Networking"},
+		{Name: "Loner", ParentTeam: "Loner"},
+	}
+
+	err := ValidateHierarchy(teams)
+	require.Error(t, err)
+	assert.Equal(t, "team hierarchy contains a cycle: Platform -> Networking -> Storage -> Platform\n"+
+		"team hierarchy contains a cycle: Loner -> Loner", err.Error())
+}
+
+func TestValidateHierarchyReportsUnknownParents(t *testing.T) {
+	teams := []model.Team{{Name: "Storage", ParentTeam: "Platfrom"}}
+
+	err := ValidateHierarchy(teams)
+	require.Error(t, err)
+	assert.Equal(t, `team "Storage" has unknown parent team "Platfrom"`, err.Error())
+}
+
+func TestValidateUniqueNamesReportsDuplicateTeams(t *testing.T) {
+	teams, files, err := LoadEntities[model.Team]("testdata/duplicates", "teams")
+	require.NoError(t, err)
+
+	err = ValidateUniqueNames(teams, files)
+	require.Error(t, err)
+	assert.Equal(t, `name "Platform" is defined 2 times, in `+
+		filepath.Join("testdata/duplicates", "teams.yaml")+", "+filepath.Join("testdata/duplicates/teams", "more.yaml"), err.Error())
+}
+
+func TestValidateUniqueNamesReportsDuplicatePeople(t *testing.T) {
+	people, files, err := LoadEntities[model.Person]("testdata/duplicates", "people")
+	require.NoError(t, err)
+
+	err = ValidateUniqueNames(people, files)
+	require.Error(t, err)
+	assert.Equal(t, `name "Alice Smith" is defined 2 times, in `+filepath.Join("testdata/duplicates", "people.yaml"), err.Error())
+}
+
+func TestValidateUniqueNamesReportsNamesDifferingOnlyByWhitespace(t *testing.T) {
+	people, files, err := LoadEntities[model.Person]("testdata/whitespace", "people")
+	require.NoError(t, err)
+
+	err = ValidateUniqueNames(people, files)
+	require.Error(t, err)
+	assert.Equal(t, `name "Alice Smith" is defined 2 times, in `+filepath.Join("testdata/whitespace", "people.yaml"), err.Error())
+}
+
+func TestValidateUniqueNames(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}, {Name: "Bob Jones"}}
+
+	assert.NoError(t, ValidateUniqueNames(people, []string{"people.yaml", "people.yaml"}))
+}
+
+func TestValidateMembersResolvesIDs(t *testing.T) {
+	people := []model.Person{
+		{Name: "Chris Taylor", ID: "ctaylor1"},
+		{Name: "Chris Taylor", ID: "ctaylor2"},
+		{Name: "Alice Smith", ID: "asmith"},
+	}
+	teams := []model.Team{{Name: "Platform", Members: []string{"ctaylor1", "ctaylor2", "Alice Smith", "asmith"}}}
+
+	assert.NoError(t, ValidateMembers(people, teams))
+}
+
+func TestValidateMembersReportsAmbiguousNames(t *testing.T) {
+	people := []model.Person{
+		{Name: "Chris Taylor", ID: "ctaylor1"},
+		{Name: "Chris Taylor", ID: "ctaylor2"},
+	}
+	teams := []model.Team{{Name: "Platform", Members: []string{"Chris Taylor", "ctaylor3"}}}
+
+	err := ValidateMembers(people, teams)
+	require.Error(t, err)
+	assert.Equal(t, `team "Platform" has unknown members: "ctaylor3"
+team "Platform" has members shared by more than one person, which must be referred to by ID: "Chris Taylor"`, err.Error())
+}
+
+func TestValidateMembershipByID(t *testing.T) {
+	people := []model.Person{{Name: "Chris Taylor", ID: "ctaylor1"}, {Name: "Chris Taylor", ID: "ctaylor2"}}
+	teams := []model.Team{{Name: "Platform", Members: []string{"ctaylor1"}}}
+
+	err := ValidateMembership(people, teams)
+	assert.EqualError(t, err, `people not on any team: "Chris Taylor" (id "ctaylor2")`)
+
+	teams[0].Members = append(teams[0].Members, "ctaylor2")
+	assert.NoError(t, ValidateMembership(people, teams))
+}
+
+func TestValidateUniqueNamesAllowsPeopleWithIDs(t *testing.T) {
+	people := []model.Person{{Name: "Chris Taylor", ID: "ctaylor1"}, {Name: "Chris Taylor", ID: "ctaylor2"}}
+
+	assert.NoError(t, ValidateUniqueNames(people, []string{"people.yaml", "people.yaml"}))
+}
+
+func TestValidateUniqueIDs(t *testing.T) {
+	people := []model.Person{
+		{Name: "Chris Taylor", ID: "ctaylor"},
+		{Name: "Alice Smith"},
+		{Name: "Bob Jones"},
+		{Name: "Christine Taylor", ID: "ctaylor"},
+	}
+
+	err := ValidateUniqueIDs(people, []string{"people.yaml", "people.yaml", "people.yaml", "people/more.yaml"})
+	assert.EqualError(t, err, `id "ctaylor" is used 2 times, in people.yaml, people/more.yaml`)
+
+	assert.NoError(t, ValidateUniqueIDs(people[:3], []string{"people.yaml", "people.yaml", "people.yaml"}))
+}
@@ -0,0 +1,102 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// versionTemplate is the format of the generated version file, taking the package name, the source
+// checksum and the generation time.
+const versionTemplate = `package %s
+
+// SourceChecksum is the SHA-256 checksum of the source files the data was generated from.
+var SourceChecksum = %q
+
+// GeneratedAt is when the data was generated from source files with this checksum, in RFC 3339 format.
+var GeneratedAt = %q
+`
+
+// generatedVersion matches the values in a previously generated version file.
+var generatedVersion = regexp.MustCompile(`(?m)^var SourceChecksum = "([0-9a-f]*)"$[\s\S]*^var GeneratedAt = "([^"]*)"$`)
+
+// sourceChecksum computes a checksum over the contents of the given files, in order.
+//
+// Only the contents are checksummed, not the paths, so the checksum does not depend on how the data
+// directory was given or on the working directory. Each file is only included once, however many
+// times or by whichever path it is listed.
+//
+// Returns:
+//   - string: The hexadecimal SHA-256 checksum.
+//   - error: An error if a file cannot be read. Returns nil if successful.
+func sourceChecksum(files []string) (string, error) {
+	hash := sha256.New()
+	seen := make(map[string]bool)
+	for _, file := range files {
+		key, err := filepath.Abs(file)
+		if err != nil {
+			return "", err
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%d\x00", len(data))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// generateVersionCodeString generates the code for the version file.
+//
+// The generation time is carried over from the previously generated code if the checksum has not
+// changed, so that regenerating unchanged data leaves the file as it was. Otherwise it is now.
+//
+// Parameters:
+//   - packageName: The name of the package the generated code belongs to.
+//   - checksum: The checksum of the source files.
+//   - previous: The previously generated code, or an empty string if there is none.
+//   - now: The current time.
+//
+// Returns:
+//   - string: The generated code string.
+func generateVersionCodeString(packageName string, checksum string, previous string, now time.Time) string {
+	generatedAt := now.UTC().Format(time.RFC3339)
+	if match := generatedVersion.FindStringSubmatch(previous); match != nil && match[1] == checksum {
+		generatedAt = match[2]
+	}
+	return fmt.Sprintf(versionTemplate, packageName, checksum, generatedAt)
+}
+
+// GenerateVersionFile generates a Go code file recording a checksum of the source files and when the
+// data was generated from them.
+//
+// The generation time only changes when the checksum does, so the file is not rewritten when the
+// source data is unchanged.
+//
+// Parameters:
+//   - sourceFiles: The files the data was generated from, as returned by LoadEntities.
+//   - options: The package and directory of the generated file. The variable name is not used.
+//
+// The function does not return any values, but it will log fatal errors if any step in the process fails.
+func GenerateVersionFile(sourceFiles []string, options Options) {
+	options = options.withDefaults("")
+	checksum, err := sourceChecksum(sourceFiles)
+	if err != nil {
+		log.Fatalf("failed to checksum source files: %v", err)
+	}
+
+	versionFile := filepath.Join(options.OutputDir, "version_data.go")
+	previous, _ := os.ReadFile(versionFile)
+	writeCodeFile(versionFile, generateVersionCodeString(options.Package, checksum, string(previous), time.Now()))
+}
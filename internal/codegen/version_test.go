@@ -0,0 +1,60 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceChecksum(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.yaml"), filepath.Join(dir, "second.yaml")
+	require.NoError(t, os.WriteFile(first, []byte("- name: Alice Smith\n"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("- name: Bob Jones\n"), 0o644))
+
+	checksum, err := sourceChecksum([]string{first, second})
+	require.NoError(t, err)
+	assert.Len(t, checksum, 64)
+
+	repeated, err := sourceChecksum([]string{first, first, second})
+	require.NoError(t, err)
+	assert.Equal(t, checksum, repeated)
+
+	repeated, err = sourceChecksum([]string{dir + "/./first.yaml", first, second})
+	require.NoError(t, err)
+	assert.Equal(t, checksum, repeated, "the same file given by another path is only included once")
+
+	elsewhere := t.TempDir()
+	copies := []string{filepath.Join(elsewhere, "first.yaml"), filepath.Join(elsewhere, "second.yaml")}
+	require.NoError(t, os.WriteFile(copies[0], []byte("- name: Alice Smith\n"), 0o644))
+	require.NoError(t, os.WriteFile(copies[1], []byte("- name: Bob Jones\n"), 0o644))
+	moved, err := sourceChecksum(copies)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, moved, "the checksum does not depend on where the files are")
+
+	require.NoError(t, os.WriteFile(second, []byte("- name: Carol White\n"), 0o644))
+	changed, err := sourceChecksum([]string{first, second})
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, changed)
+
+	_, err = sourceChecksum([]string{filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestGenerateVersionCodeString(t *testing.T) {
+	generated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := generated.Add(time.Hour)
+
+	code := generateVersionCodeString(DefaultPackage, "abc123", "", generated)
+	assert.True(t, strings.HasPrefix(code, "package generated_data\n"))
+	assert.Contains(t, code, `var SourceChecksum = "abc123"`)
+	assert.Contains(t, code, `var GeneratedAt = "2024-05-01T12:00:00Z"`)
+
+	assert.Equal(t, code, generateVersionCodeString(DefaultPackage, "abc123", code, later), "the time is kept while the checksum is unchanged")
+	assert.Contains(t, generateVersionCodeString(DefaultPackage, "def456", code, later), `var GeneratedAt = "2024-05-01T13:00:00Z"`)
+}
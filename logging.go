@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLogger returns middleware which logs each request as a single JSON line.
+//
+// Each line records the request's method, path, response status, latency in milliseconds and the
+// client's IP address. Requests to any of the skipped paths are not logged.
+func requestLogger(out io.Writer, skipPaths ...string) gin.HandlerFunc {
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		if skip[path] {
+			return
+		}
+		logger.Info("request",
+			"method", c.Request.Method,
+			"path", path,
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
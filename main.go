@@ -1,29 +1,615 @@
 package main
 
-//go:codegen go run codegen.go
+//go:generate go run generate.go
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/rvodden/teams/internal/generated_data"
+	"github.com/rvodden/teams/model"
 )
 
-// getAlbums responds with the list of all albums as JSON.
+// findPeople returns the person with the given ID or, failing that, every person whose name matches
+// the given name, ignoring case.
+func findPeople(name string) []model.Person {
+	if person, ok := peopleLookup.get().byID[name]; ok {
+		return []model.Person{person}
+	}
+	matches := []model.Person{}
+	for _, person := range generated_data.People {
+		if strings.EqualFold(person.Name, name) {
+			matches = append(matches, person)
+		}
+	}
+	return matches
+}
+
+// personFromPath returns the person named in the URL. If there is no such person it responds with
+// 404 Not Found, and if the name is shared by more than one person it responds with 409 Conflict and
+// the candidates, who must be referred to by ID; in either case it reports false.
+func personFromPath(c *gin.Context) (model.Person, bool) {
+	matches := findPeople(c.Param("name"))
+	switch len(matches) {
+	case 0:
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "person not found"})
+		return model.Person{}, false
+	case 1:
+		return matches[0], true
+	default:
+		c.IndentedJSON(http.StatusConflict, gin.H{
+			"error":      "name is shared by more than one person, who must be referred to by ID",
+			"candidates": matches,
+		})
+		return model.Person{}, false
+	}
+}
+
+// findTeam returns the team whose name matches the given name, ignoring case.
+func findTeam(name string) (model.Team, bool) {
+	for _, team := range currentTeams.get() {
+		if strings.EqualFold(team.Name, name) {
+			return team, true
+		}
+	}
+	return model.Team{}, false
+}
+
+// people indexes the people by ID and by name, for resolving references to them.
+type people struct {
+	byID   map[string]model.Person
+	byName map[string][]model.Person
+}
+
+// peopleLookup is the index of the generated people.
+var peopleLookup = newCached(func() people {
+	lookup := people{
+		byID:   make(map[string]model.Person, len(generated_data.People)),
+		byName: make(map[string][]model.Person, len(generated_data.People)),
+	}
+	for _, person := range generated_data.People {
+		if person.ID != "" {
+			lookup.byID[person.ID] = person
+		}
+		lookup.byName[person.Name] = append(lookup.byName[person.Name], person)
+	}
+	return lookup
+})
+
+// resolveMember returns the person a team member refers to.
+//
+// A member refers to the person with a matching ID if there is one, and otherwise to the person with
+// exactly that name. A name shared by several people does not refer to any of them.
+func resolveMember(member string) (model.Person, bool) {
+	lookup := peopleLookup.get()
+	if person, ok := lookup.byID[member]; ok {
+		return person, true
+	}
+	if matches := lookup.byName[member]; len(matches) == 1 {
+		return matches[0], true
+	}
+	return model.Person{}, false
+}
+
+// personKey returns the string which distinguishes the person from everyone else: their ID if they
+// have one, and otherwise their name.
+func personKey(person model.Person) string {
+	if person.ID != "" {
+		return person.ID
+	}
+	return person.Name
+}
+
+// resolveMembers looks up each of the team's members among the people, in the order the members are
+// listed, as described by resolveMember. Any members which do not refer to a person are returned
+// separately as unresolved.
+func resolveMembers(team model.Team) (resolved []model.Person, unresolved []string) {
+	resolved, unresolved = []model.Person{}, []string{}
+	for _, member := range team.Members {
+		if person, ok := resolveMember(member); ok {
+			resolved = append(resolved, person)
+		} else {
+			unresolved = append(unresolved, member)
+		}
+	}
+	return resolved, unresolved
+}
+
+// references returns the member strings which refer to the person: their ID, if they have one, and
+// their name, if it refers to them.
+func references(person model.Person) []string {
+	var refs []string
+	if person.ID != "" {
+		refs = append(refs, person.ID)
+	}
+	if resolved, ok := resolveMember(person.Name); ok && resolved == person {
+		refs = append(refs, person.Name)
+	}
+	return refs
+}
+
+// paginate returns the page of items selected by the limit and offset query parameters.
+//
+// Both parameters are optional: without them the whole slice is returned. An offset past the end
+// of the slice results in an empty page. An error is returned if either parameter is not a
+// non-negative integer.
+func paginate[T any](c *gin.Context, items []T) ([]T, error) {
+	offset, limit := 0, len(items)
+
+	if value, ok := c.GetQuery("offset"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid offset %q", value)
+		}
+		offset = parsed
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid limit %q", value)
+		}
+		limit = parsed
+	}
+
+	if offset >= len(items) {
+		return []T{}, nil
+	}
+	end := len(items)
+	if limit < end-offset {
+		end = offset + limit
+	}
+	return items[offset:end], nil
+}
+
+// getPeople responds with the list of all people, as JSON unless the format query parameter selects
+// another format.
+//
+// If the team query parameter is given, only the members of that team are returned, and if the role
+// query parameter is given, only the people with exactly that role. The fields query
+// parameter selects a comma-separated subset of each person's fields to return. The limit and
+// offset query parameters select a page of the results, and the X-Total-Count header carries the
+// number of people before pagination.
 func getPeople(c *gin.Context) {
-	c.IndentedJSON(http.StatusOK, generated_data.People)
+	listPeople(c, c.DefaultQuery("format", "json"))
+}
+
+// getPeopleCSV responds with the list of all people as CSV. It accepts the same query parameters as getPeople.
+func getPeopleCSV(c *gin.Context) {
+	listPeople(c, "csv")
+}
+
+// listPeople responds with the list of people selected by the request's query parameters in the given format.
+func listPeople(c *gin.Context, format string) {
+	if format != "json" && format != "csv" && format != "ndjson" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+		return
+	}
+
+	var selection fieldSelection
+	if spec, ok := c.GetQuery("fields"); ok {
+		if format == "csv" {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "fields cannot be selected for csv"})
+			return
+		}
+		var err error
+		selection, err = parseFields[model.Person](spec)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	people := generated_data.People
+
+	if teamName, ok := c.GetQuery("team"); ok {
+		team, found := findTeam(teamName)
+		if !found {
+			c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			return
+		}
+		people, _ = resolveMembers(team)
+	}
+	if role, ok := c.GetQuery("role"); ok {
+		people = peopleWithRole(people, role)
+	}
+
+	page, err := paginate(c, people)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Only a valid request can be answered with 304 Not Modified.
+	representation := ""
+	if format == "json" {
+		representation = jsonRepresentation(c)
+	}
+	if notModified(c, representation) {
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(len(people)))
+	switch format {
+	case "csv":
+		writeCSV(c, "people.csv", page)
+	case "ndjson":
+		if selection != nil {
+			writeNDJSON(c, project(page, selection))
+		} else {
+			writeNDJSON(c, page)
+		}
+	default:
+		if selection != nil {
+			writeJSON(c, http.StatusOK, project(page, selection))
+		} else {
+			writeJSON(c, http.StatusOK, page)
+		}
+	}
+}
+
+// peopleWithRole returns the people whose role is exactly the given role.
+func peopleWithRole(people []model.Person, role string) []model.Person {
+	matches := []model.Person{}
+	for _, person := range people {
+		if person.Role == role {
+			matches = append(matches, person)
+		}
+	}
+	return matches
+}
+
+// distinctRoles returns the roles of the given people, sorted and without duplicates. People without
+// a role are ignored.
+func distinctRoles(people []model.Person) []string {
+	roles := []string{}
+	for _, person := range people {
+		if person.Role != "" {
+			roles = append(roles, person.Role)
+		}
+	}
+	slices.Sort(roles)
+	return slices.Compact(roles)
+}
+
+// getRoles responds with the distinct roles of the people.
+func getRoles(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, distinctRoles(generated_data.People))
 }
 
+// searchPeople returns the people whose name or email contains the query, ignoring case.
+func searchPeople(people []model.Person, query string) []model.Person {
+	query = strings.ToLower(query)
+	matches := []model.Person{}
+	for _, person := range people {
+		if strings.Contains(strings.ToLower(person.Name), query) || strings.Contains(strings.ToLower(person.Email), query) {
+			matches = append(matches, person)
+		}
+	}
+	return matches
+}
+
+// getPeopleSearch responds with the people whose name or email contains the q query parameter.
+//
+// A missing or empty query is rejected with 400 Bad Request rather than matching everyone.
+func getPeopleSearch(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "missing search query"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, searchPeople(generated_data.People, query))
+}
+
+// members returns the set of member strings listed by any of the given teams.
+func members(teams []model.Team) map[string]bool {
+	names := make(map[string]bool)
+	for _, team := range teams {
+		for _, member := range team.Members {
+			names[member] = true
+		}
+	}
+	return names
+}
+
+// unassignedPeople returns the people who are not members of any of the given teams.
+func unassignedPeople(people []model.Person, teams []model.Team) []model.Person {
+	assigned := members(teams)
+	unassigned := []model.Person{}
+	for _, person := range people {
+		if !slices.ContainsFunc(references(person), func(ref string) bool { return assigned[ref] }) {
+			unassigned = append(unassigned, person)
+		}
+	}
+	return unassigned
+}
+
+// getPeopleUnassigned responds with the people who are not members of any active team.
+func getPeopleUnassigned(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, unassignedPeople(generated_data.People, activeTeams(currentTeams.get())))
+}
+
+// rosterDiff describes how a roster of people differs from the served people, by name.
+type rosterDiff struct {
+	ServerOnly []string `json:"server_only"`
+	PostedOnly []string `json:"posted_only"`
+	Both       []string `json:"both"`
+}
+
+// diffRoster compares the names of the served people with those of the posted people. Each list in
+// the result is sorted.
+func diffRoster(served []model.Person, posted []model.Person) rosterDiff {
+	postedNames := make(map[string]bool, len(posted))
+	for _, person := range posted {
+		postedNames[person.Name] = true
+	}
+	servedNames := make(map[string]bool, len(served))
+	for _, person := range served {
+		servedNames[person.Name] = true
+	}
+
+	diff := rosterDiff{ServerOnly: []string{}, PostedOnly: []string{}, Both: []string{}}
+	for name := range servedNames {
+		if postedNames[name] {
+			diff.Both = append(diff.Both, name)
+		} else {
+			diff.ServerOnly = append(diff.ServerOnly, name)
+		}
+	}
+	for name := range postedNames {
+		if !servedNames[name] {
+			diff.PostedOnly = append(diff.PostedOnly, name)
+		}
+	}
+	sort.Strings(diff.ServerOnly)
+	sort.Strings(diff.PostedOnly)
+	sort.Strings(diff.Both)
+	return diff
+}
+
+// postPeopleDiff compares the JSON array of people in the request body with the served people,
+// responding with the names only on the server, only in the request, and in both.
+func postPeopleDiff(c *gin.Context) {
+	var posted []model.Person
+	if err := c.ShouldBindJSON(&posted); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, diffRoster(generated_data.People, posted))
+}
+
+// getPersonByName responds with the person named in the URL, or 404 if there is no such person.
+func getPersonByName(c *gin.Context) {
+	person, ok := personFromPath(c)
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, person)
+}
+
+// getPersonTeams responds with the names of the teams that the person named in the URL belongs to.
+func getPersonTeams(c *gin.Context) {
+	person, ok := personFromPath(c)
+	if !ok {
+		return
+	}
+	teams := []string{}
+	for _, ref := range references(person) {
+		teams = append(teams, currentIndexes.get().teamsByPerson[ref]...)
+	}
+	slices.Sort(teams)
+	c.IndentedJSON(http.StatusOK, slices.Compact(teams))
+}
+
+// getTeams responds with the list of all active teams, as JSON unless the format query parameter
+// selects newline-delimited JSON.
+//
+// Inactive teams are included if the include_inactive query parameter is true. The sort query parameter
+// orders the teams by name or size, as described by teamOrderings.
 func getTeams(c *gin.Context) {
-	c.IndentedJSON(http.StatusOK, generated_data.Teams)
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "ndjson" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+		return
+	}
+
+	includeInactive := false
+	if value, ok := c.GetQuery("include_inactive"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid include_inactive %q", value)})
+			return
+		}
+		includeInactive = parsed
+	}
+
+	sortKey := c.Query("sort")
+	if _, ok := teamOrderings[sortKey]; !ok {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported sort %q", sortKey)})
+		return
+	}
+
+	// Only a valid request can be answered with 304 Not Modified.
+	representation := ""
+	if format == "json" {
+		representation = jsonRepresentation(c)
+	}
+	if notModified(c, representation) {
+		return
+	}
+
+	teams := currentTeams.get()
+	if !includeInactive {
+		teams = activeTeams(teams)
+	}
+	teams = sortTeams(teams, sortKey)
+	if format == "ndjson" {
+		writeNDJSON(c, teams)
+		return
+	}
+	writeJSON(c, http.StatusOK, teams)
 }
 
-func main() {
-	router := gin.Default()
+// teamOrderings are the orderings which can be applied to the list of teams, by the value of the sort
+// query parameter. A leading - reverses the order. The empty key keeps the generated order.
+var teamOrderings = map[string]func(a, b model.Team) int{
+	"":      nil,
+	"name":  func(a, b model.Team) int { return strings.Compare(a.Name, b.Name) },
+	"-name": func(a, b model.Team) int { return strings.Compare(b.Name, a.Name) },
+	"size":  func(a, b model.Team) int { return len(a.Members) - len(b.Members) },
+	"-size": func(a, b model.Team) int { return len(b.Members) - len(a.Members) },
+}
+
+// sortTeams returns a copy of the teams sorted by the ordering with the given key, which must be one
+// of teamOrderings, or the teams themselves for the empty key. Teams which compare equal keep their
+// relative order.
+func sortTeams(teams []model.Team, key string) []model.Team {
+	compare := teamOrderings[key]
+	if compare == nil {
+		return teams
+	}
+	sorted := slices.Clone(teams)
+	slices.SortStableFunc(sorted, compare)
+	return sorted
+}
+
+// activeTeams returns the teams which are active.
+func activeTeams(teams []model.Team) []model.Team {
+	active := []model.Team{}
+	for _, team := range teams {
+		if team.Active {
+			active = append(active, team)
+		}
+	}
+	return active
+}
+
+// getTeamByName responds with the team named in the URL, or 404 if there is no such team.
+func getTeamByName(c *gin.Context) {
+	team, ok := findTeam(c.Param("name"))
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// getTeamMembers responds with the members of the team named in the URL, resolved to people.
+//
+// Members which do not refer to a known person are listed in the unresolved array of the response.
+func getTeamMembers(c *gin.Context) {
+	team, ok := findTeam(c.Param("name"))
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	members, unresolved := resolveMembers(team)
+	c.IndentedJSON(http.StatusOK, gin.H{"members": members, "unresolved": unresolved})
+}
+
+// healthz responds with 200 OK once the generated data has been loaded, and 503 Service Unavailable otherwise.
+func healthz(c *gin.Context) {
+	if generated_data.People == nil || generated_data.Teams == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// getSubTeams responds with the names of the child teams of the team named in the URL.
+func getSubTeams(c *gin.Context) {
+	team, ok := findTeam(c.Param("name"))
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	subTeams, ok := currentIndexes.get().subTeams[team.Name]
+	if !ok {
+		subTeams = []string{}
+	}
+	c.IndentedJSON(http.StatusOK, subTeams)
+}
+
+// setupRouter creates the gin engine and registers all of the API routes on it.
+//
+// Requests to /healthz are not logged, as they are made frequently by load balancers. Requests to
+// /healthz and /metrics are not rate limited, so that probes and scrapes are never refused.
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Printf("invalid TEAMS_TRUSTED_PROXIES: %v, trusting no proxies", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.Use(requestLogger(gin.DefaultWriter, "/healthz"), gin.Recovery())
+	// Metrics are recorded before any middleware which may refuse the request, so that refusals are counted.
+	metrics := newMetrics()
+	router.Use(metrics.middleware())
+	router.Use(cors(corsAllowedOrigins()))
+	router.Use(rateLimit(rateLimitFromEnv(), "/healthz", "/metrics"))
+	router.Use(compress(gzipMinSize))
+
+	router.GET("/metrics", gin.WrapH(metrics.handler()))
+
+	router.GET("/healthz", healthz)
+	router.GET("/stats", getStats)
+	router.GET("/org", getOrg)
+	router.GET("/version", getVersion)
+	router.GET("/openapi.json", getOpenAPI)
+	router.GET("/roles", getRoles)
 	router.GET("/people", getPeople)
+	router.GET("/people.csv", getPeopleCSV)
+	router.GET("/people/search", getPeopleSearch)
+	router.GET("/people/unassigned", getPeopleUnassigned)
+	router.POST("/people/diff", postPeopleDiff)
+	router.GET("/people/:name", getPersonByName)
+	router.GET("/people/:name/teams", getPersonTeams)
 	router.GET("/teams", getTeams)
+	router.GET("/teams.dot", getTeamsDOT)
+	router.POST("/teams/validate", postTeamsValidate)
+	router.GET("/teams/:name", getTeamByName)
+	router.GET("/teams/:name/members", getTeamMembers)
+	router.GET("/teams/:name/subteams", getSubTeams)
+	if overridesEnabled() {
+		router.PUT("/teams/:name", putTeam)
+		router.DELETE("/teams/:name/override", deleteTeamOverride)
+	}
+	return router
+}
 
-	err := router.Run("localhost:8080")
+func main() {
+	addr := flag.String("addr", "", "address to listen on (defaults to $TEAMS_ADDR, then "+defaultAddr+")")
+	shutdownTimeout := flag.Duration("shutdown-timeout", defaultShutdownTimeout, "how long to wait for in-flight requests when shutting down")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := loadRemoteTeams(ctx, dataURL()); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	listener, err := net.Listen("tcp", listenAddress(*addr))
 	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	log.Printf("listening on %s", listener.Addr())
+	if rateLimitFromEnv() > 0 && len(trustedProxies()) == 0 {
+		log.Printf("warning: rate limiting is on but TEAMS_TRUSTED_PROXIES is not set, so all clients behind a proxy share one limit")
+	}
+
+	server := newServer(setupRouter())
+	if err := serve(ctx, server, listener, *shutdownTimeout); err != nil {
+		log.Fatalf("failed to run server: %v", err)
 	}
 }
@@ -0,0 +1,584 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/internal/generated_data"
+	"github.com/rvodden/teams/model"
+)
+
+// seedData replaces the generated data with the given people and teams for the duration of the test.
+func seedData(t *testing.T, people []model.Person, teams []model.Team) {
+	t.Helper()
+	seed(t, &generated_data.People, people)
+	seed(t, &generated_data.Teams, teams)
+}
+
+// seed replaces a generated data variable with the given value for the duration of the test.
+func seed[T any](t *testing.T, variable *T, value T) {
+	t.Helper()
+	original := *variable
+	*variable = value
+	resetCaches()
+	t.Cleanup(func() {
+		*variable = original
+		resetCaches()
+	})
+}
+
+// performRequest sends a request through a freshly configured router and returns the recorded response.
+func performRequest(t *testing.T, method string, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveRequest(t, httptest.NewRequest(method, target, nil))
+}
+
+// serveRequest sends the given request through a freshly configured router and returns the recorded response.
+func serveRequest(t *testing.T, request *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	setupRouter().ServeHTTP(recorder, request)
+	return recorder
+}
+
+var testPeople = []model.Person{
+	{Name: "Alice Smith", SlackChannel: "D001", Email: "alice@example.com"},
+	{Name: "Bob Jones", SlackChannel: "D002", Email: "bob@example.com"},
+	{Name: "Carol White", SlackChannel: "D003", Email: "carol@example.com"},
+}
+
+var testTeams = []model.Team{
+	{Name: "Platform", InternalSlackChannel: "C001", Members: []string{"Alice Smith", "Bob Jones"}, Active: true},
+	{Name: "Data", InternalSlackChannel: "C002", Members: []string{"Carol White"}, Active: true},
+}
+
+func TestGetTeamByName(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams/platform")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var team model.Team
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &team))
+	assert.Equal(t, testTeams[0], team)
+}
+
+func TestGetTeamByNameNotFound(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams/unknown")
+	require.Equal(t, http.StatusNotFound, response.Code)
+	assert.JSONEq(t, `{"error": "team not found"}`, response.Body.String())
+}
+
+func TestGetPersonByName(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people/BOB%20JONES")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var person model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &person))
+	assert.Equal(t, testPeople[1], person)
+}
+
+func TestGetPersonByNameNotFound(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people/nobody")
+	require.Equal(t, http.StatusNotFound, response.Code)
+	assert.JSONEq(t, `{"error": "person not found"}`, response.Body.String())
+}
+
+func TestGetPeopleFilteredByTeam(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?team=Platform")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var people []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &people))
+	assert.Equal(t, testPeople[:2], people)
+}
+
+func TestGetPeopleFilteredByUnknownTeam(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?team=Unknown")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestGetPeopleUnfiltered(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var people []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &people))
+	assert.Equal(t, testPeople, people)
+}
+
+func TestConditionalGet(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, target := range []string{"/teams", "/people"} {
+		t.Run(target, func(t *testing.T) {
+			first := performRequest(t, http.MethodGet, target)
+			require.Equal(t, http.StatusOK, first.Code)
+			etag := first.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			request.Header.Set("If-None-Match", etag)
+			second := serveRequest(t, request)
+			assert.Equal(t, http.StatusNotModified, second.Code)
+			assert.Empty(t, second.Body.String())
+			assert.Equal(t, etag, second.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestConditionalGetValidatesRequestFirst(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	first := performRequest(t, http.MethodGet, "/people")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	tests := []struct {
+		target   string
+		expected int
+	}{
+		{"/people?team=Nope", http.StatusNotFound},
+		{"/people?limit=bogus", http.StatusBadRequest},
+		{"/people?offset=-1", http.StatusBadRequest},
+		{"/people?fields=bogus", http.StatusBadRequest},
+		{"/teams?sort=bogus", http.StatusBadRequest},
+		{"/teams?include_inactive=bogus", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, test.target, nil)
+			request.Header.Set("If-None-Match", etag)
+			response := serveRequest(t, request)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestConditionalGetWithStaleETag(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	request := httptest.NewRequest(http.MethodGet, "/teams", nil)
+	request.Header.Set("If-None-Match", `"stale"`)
+	response := serveRequest(t, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotEmpty(t, response.Body.String())
+}
+
+func TestGetPeoplePagination(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []model.Person
+	}{
+		{"no parameters", "", testPeople},
+		{"limit only", "?limit=2", testPeople[:2]},
+		{"offset only", "?offset=1", testPeople[1:]},
+		{"limit and offset", "?limit=1&offset=1", testPeople[1:2]},
+		{"limit past the end", "?limit=10&offset=2", testPeople[2:]},
+		{"limit of zero", "?limit=0", []model.Person{}},
+		{"offset at the end", "?offset=3", []model.Person{}},
+		{"offset past the end", "?offset=10", []model.Person{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := performRequest(t, http.MethodGet, "/people"+test.query)
+			require.Equal(t, http.StatusOK, response.Code)
+			assert.Equal(t, "3", response.Header().Get("X-Total-Count"))
+
+			var people []model.Person
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &people))
+			assert.Equal(t, test.expected, people)
+		})
+	}
+}
+
+func TestGetPeopleInvalidPagination(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, query := range []string{"?limit=many", "?limit=-1", "?offset=first"} {
+		t.Run(query, func(t *testing.T) {
+			response := performRequest(t, http.MethodGet, "/people"+query)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
+func TestGetTeamMembers(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Bob Jones", "Dave Unknown", "Alice Smith", "alice smith"}},
+	}
+	seedData(t, testPeople, teams)
+
+	response := performRequest(t, http.MethodGet, "/teams/Platform/members")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var body struct {
+		Members    []model.Person `json:"members"`
+		Unresolved []string       `json:"unresolved"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, []model.Person{testPeople[1], testPeople[0]}, body.Members)
+	assert.Equal(t, []string{"Dave Unknown", "alice smith"}, body.Unresolved)
+}
+
+func TestGetTeamMembersNotFound(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams/unknown/members")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestHealthz(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/healthz")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, response.Body.String())
+}
+
+func TestHealthzUnavailable(t *testing.T) {
+	seedData(t, testPeople, nil)
+
+	response := performRequest(t, http.MethodGet, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+}
+
+func TestGetPersonTeams(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	seed(t, &generated_data.TeamsByPerson, map[string][]string{
+		"Alice Smith": {"Data", "Platform"},
+	})
+
+	response := performRequest(t, http.MethodGet, "/people/alice%20smith/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `["Data", "Platform"]`, response.Body.String())
+
+	response = performRequest(t, http.MethodGet, "/people/Carol%20White/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `[]`, response.Body.String())
+}
+
+func TestGetPersonTeamsNotFound(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people/nobody/teams")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestGetSubTeams(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform"},
+		{Name: "Storage", ParentTeam: "Platform"},
+		{Name: "Networking", ParentTeam: "Platform"},
+	}
+	seedData(t, testPeople, teams)
+	seed(t, &generated_data.SubTeams, map[string][]string{
+		"Platform": {"Networking", "Storage"},
+	})
+
+	response := performRequest(t, http.MethodGet, "/teams/platform/subteams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `["Networking", "Storage"]`, response.Body.String())
+
+	response = performRequest(t, http.MethodGet, "/teams/Storage/subteams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `[]`, response.Body.String())
+
+	response = performRequest(t, http.MethodGet, "/teams/unknown/subteams")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestSearchPeople(t *testing.T) {
+	people := []model.Person{
+		{Name: "Alice Smith", Email: "asmith@example.com"},
+		{Name: "Bob Jones", Email: "bob.alison@example.com"},
+		{Name: "Carol White"},
+	}
+
+	assert.Equal(t, people[:2], searchPeople(people, "ALI"), "matches in name and email")
+	assert.Equal(t, people[1:2], searchPeople(people, "alison@"), "match in email only")
+	assert.Equal(t, people[2:], searchPeople(people, "white"), "match in name only")
+	assert.Empty(t, searchPeople(people, "dave"))
+}
+
+func TestGetPeopleSearch(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people/search?q=smith")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var people []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &people))
+	assert.Equal(t, testPeople[:1], people)
+}
+
+func TestGetPeopleUnassigned(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith"}, {Name: "Bob Jones"}, {Name: "Carol White"}}
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice Smith"}, Active: true},
+		{Name: "Legacy", Members: []string{"Alice Smith", "Carol White"}, Active: false},
+	}
+	seedData(t, people, teams)
+
+	response := performRequest(t, http.MethodGet, "/people/unassigned")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var unassigned []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &unassigned))
+	assert.Equal(t, []model.Person{{Name: "Bob Jones"}, {Name: "Carol White"}}, unassigned)
+}
+
+func TestGetPeopleSearchWithoutQuery(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, target := range []string{"/people/search", "/people/search?q="} {
+		response := performRequest(t, http.MethodGet, target)
+		assert.Equal(t, http.StatusBadRequest, response.Code, target)
+	}
+}
+
+func TestGetTeamsHidesInactiveTeams(t *testing.T) {
+	archived := model.Team{Name: "Legacy", Members: []string{"Alice Smith"}, Active: false}
+	seedData(t, testPeople, append([]model.Team{archived}, testTeams...))
+
+	response := performRequest(t, http.MethodGet, "/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	var teams []model.Team
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &teams))
+	assert.Equal(t, testTeams, teams)
+
+	response = performRequest(t, http.MethodGet, "/teams?include_inactive=true")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &teams))
+	assert.Equal(t, append([]model.Team{archived}, testTeams...), teams)
+
+	response = performRequest(t, http.MethodGet, "/teams?include_inactive=maybe")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestGetTeamsSorted(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice Smith", "Bob Jones"}, Active: true},
+		{Name: "Data", Members: []string{"Carol White"}, Active: true},
+		{Name: "Search", Members: []string{"Alice Smith", "Bob Jones", "Carol White"}, Active: true},
+	}
+	seedData(t, testPeople, teams)
+
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"", []string{"Platform", "Data", "Search"}},
+		{"name", []string{"Data", "Platform", "Search"}},
+		{"-name", []string{"Search", "Platform", "Data"}},
+		{"size", []string{"Data", "Platform", "Search"}},
+		{"-size", []string{"Search", "Platform", "Data"}},
+	}
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			response := performRequest(t, http.MethodGet, "/teams?sort="+test.sort)
+			require.Equal(t, http.StatusOK, response.Code)
+			var sorted []model.Team
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &sorted))
+			var names []string
+			for _, team := range sorted {
+				names = append(names, team.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+	assert.Equal(t, "Platform", teams[0].Name, "the teams are sorted in a copy")
+}
+
+func TestGetTeamsWithUnsupportedSort(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams?sort=members")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "unsupported sort")
+}
+
+func TestPostPeopleDiff(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	body := `[{"Name": "Bob Jones"}, {"Name": "Dave Brown", "Email": "dave@example.com"}, {"Name": "Alice Smith"}]`
+	request := httptest.NewRequest(http.MethodPost, "/people/diff", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := serveRequest(t, request)
+
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{
+		"server_only": ["Carol White"],
+		"posted_only": ["Dave Brown"],
+		"both": ["Alice Smith", "Bob Jones"]
+	}`, response.Body.String())
+}
+
+func TestPostPeopleDiffMalformedBody(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	request := httptest.NewRequest(http.MethodPost, "/people/diff", strings.NewReader(`{"Name": "Bob Jones"`))
+	response := serveRequest(t, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+// namesakes are two people with the same name, distinguished by ID.
+var namesakes = []model.Person{
+	{Name: "Chris Taylor", ID: "ctaylor1", Role: "Engineer"},
+	{Name: "Chris Taylor", ID: "ctaylor2", Role: "Designer"},
+	{Name: "Alice Smith", ID: "asmith"},
+}
+
+// namesakeTeams refer to the namesakes by ID, and to Alice Smith by name.
+var namesakeTeams = []model.Team{
+	{Name: "Platform", Members: []string{"ctaylor1", "Alice Smith"}, Active: true},
+	{Name: "Design", Members: []string{"ctaylor2", "Chris Taylor"}, Active: true},
+}
+
+func TestGetPersonByID(t *testing.T) {
+	seedData(t, namesakes, namesakeTeams)
+
+	for id, role := range map[string]string{"ctaylor1": "Engineer", "ctaylor2": "Designer"} {
+		response := performRequest(t, http.MethodGet, "/people/"+id)
+		require.Equal(t, http.StatusOK, response.Code)
+		var person model.Person
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &person))
+		assert.Equal(t, model.Person{Name: "Chris Taylor", ID: id, Role: role}, person)
+	}
+}
+
+func TestGetPersonBySharedName(t *testing.T) {
+	seedData(t, namesakes, namesakeTeams)
+
+	for _, target := range []string{"/people/Chris%20Taylor", "/people/chris%20taylor/teams"} {
+		response := performRequest(t, http.MethodGet, target)
+		require.Equal(t, http.StatusConflict, response.Code, target)
+		var body struct {
+			Error      string         `json:"error"`
+			Candidates []model.Person `json:"candidates"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Contains(t, body.Error, "referred to by ID")
+		assert.Equal(t, namesakes[:2], body.Candidates)
+	}
+
+	response := performRequest(t, http.MethodGet, "/people/Alice%20Smith")
+	assert.Equal(t, http.StatusOK, response.Code, "a name which is not shared still finds its person")
+}
+
+func TestGetTeamMembersResolvesIDs(t *testing.T) {
+	seedData(t, namesakes, namesakeTeams)
+
+	response := performRequest(t, http.MethodGet, "/teams/Platform/members")
+	require.Equal(t, http.StatusOK, response.Code)
+	var body struct {
+		Members    []model.Person `json:"members"`
+		Unresolved []string       `json:"unresolved"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, []model.Person{namesakes[0], namesakes[2]}, body.Members)
+	assert.Empty(t, body.Unresolved)
+
+	response = performRequest(t, http.MethodGet, "/teams/Design/members")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, []model.Person{namesakes[1]}, body.Members)
+	assert.Equal(t, []string{"Chris Taylor"}, body.Unresolved, "a shared name is ambiguous")
+}
+
+func TestGetPersonTeamsByID(t *testing.T) {
+	seedData(t, namesakes, namesakeTeams)
+	seed(t, &generated_data.TeamsByPerson, map[string][]string{
+		"ctaylor1":     {"Platform"},
+		"Alice Smith":  {"Platform"},
+		"ctaylor2":     {"Design"},
+		"Chris Taylor": {"Design"},
+	})
+
+	for id, expected := range map[string][]string{"ctaylor1": {"Platform"}, "ctaylor2": {"Design"}, "asmith": {"Platform"}} {
+		response := performRequest(t, http.MethodGet, "/people/"+id+"/teams")
+		require.Equal(t, http.StatusOK, response.Code)
+		var teams []string
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &teams))
+		assert.Equal(t, expected, teams, id)
+	}
+}
+
+func TestGetPeopleUnassignedByID(t *testing.T) {
+	seedData(t, namesakes, namesakeTeams[1:])
+
+	response := performRequest(t, http.MethodGet, "/people/unassigned")
+	require.Equal(t, http.StatusOK, response.Code)
+	var unassigned []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &unassigned))
+	assert.Equal(t, []model.Person{namesakes[0], namesakes[2]}, unassigned)
+}
+
+func TestDistinctRoles(t *testing.T) {
+	people := []model.Person{
+		{Name: "Alice Smith", Role: "Engineer"},
+		{Name: "Bob Jones", Role: "Manager"},
+		{Name: "Carol White"},
+		{Name: "Dave Brown", Role: "Engineer"},
+		{Name: "Erin Green", Role: "Designer"},
+	}
+
+	assert.Equal(t, []string{"Designer", "Engineer", "Manager"}, distinctRoles(people))
+	assert.Equal(t, []string{}, distinctRoles(nil))
+}
+
+func TestGetRoles(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith", Role: "Engineer"}, {Name: "Bob Jones", Role: "Manager"}, {Name: "Carol White", Role: "Engineer"}}
+	seedData(t, people, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/roles")
+	require.Equal(t, http.StatusOK, response.Code)
+	var roles []string
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &roles))
+	assert.Equal(t, []string{"Engineer", "Manager"}, roles)
+}
+
+func TestGetPeopleFilteredByRole(t *testing.T) {
+	people := []model.Person{{Name: "Alice Smith", Role: "Engineer"}, {Name: "Bob Jones", Role: "Manager"}, {Name: "Carol White", Role: "Engineer"}}
+	seedData(t, people, testTeams)
+
+	response := performRequest(t, http.MethodGet, "/people?role=Engineer")
+	require.Equal(t, http.StatusOK, response.Code)
+	var filtered []model.Person
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &filtered))
+	assert.Equal(t, []model.Person{people[0], people[2]}, filtered)
+	assert.Equal(t, "2", response.Header().Get("X-Total-Count"))
+
+	response = performRequest(t, http.MethodGet, "/people?role=engineer")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, "[]", response.Body.String(), "roles are matched exactly")
+
+	response = performRequest(t, http.MethodGet, "/people?role=Astronaut")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, "[]", response.Body.String())
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+// metrics holds the Prometheus registry for a router along with the request metrics recorded in it.
+type metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// newMetrics creates a registry containing the request metrics, gauges for the number of teams and
+// people, and the standard Go runtime and process collectors.
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests, by route and response status.",
+		}, []string{"path", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests, by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"path"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "teams_total",
+			Help: "Number of teams.",
+		}, func() float64 { return float64(len(generated_data.Teams)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "people_total",
+			Help: "Number of people.",
+		}, func() float64 { return float64(len(generated_data.People)) }),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// middleware returns middleware which records the count and duration of each request.
+//
+// Requests are labelled with the route pattern, such as /teams/:name, rather than the request path,
+// so that the number of series stays bounded. Requests which match no route are labelled unmatched.
+func (m *metrics) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		m.requests.WithLabelValues(path, strconv.Itoa(c.Writer.Status())).Inc()
+		m.duration.WithLabelValues(path).Observe(time.Since(start).Seconds())
+	}
+}
+
+// handler returns the handler which serves the metrics in the Prometheus exposition format.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
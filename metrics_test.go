@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	for i := 0; i < 2; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teams/Platform", nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teams/unknown", nil))
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	body := response.Body.String()
+	assert.Contains(t, body, `http_requests_total{path="/teams/:name",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{path="/teams/:name",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{path="/teams/:name"} 3`)
+	assert.Contains(t, body, "teams_total 2")
+	assert.Contains(t, body, "people_total 3")
+}
+
+func TestMetricsCountRefusedRequests(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_RATE_LIMIT", "1")
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	for i := 0; i < 3; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teams", nil))
+	}
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	body := response.Body.String()
+	assert.Contains(t, body, `http_requests_total{path="/teams",status="200"} 1`)
+	assert.Contains(t, body, `http_requests_total{path="/teams",status="429"} 2`)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONFieldName returns the name encoding/json uses for a struct field, and whether the field is
+// omitted when empty. It reports false if the field is not encoded at all.
+func JSONFieldName(field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, true
+}
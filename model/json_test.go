@@ -0,0 +1,43 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFieldName(t *testing.T) {
+	type example struct {
+		Plain    string
+		Renamed  string `json:"renamed"`
+		Optional string `json:"optional,omitempty"`
+		Unnamed  string `json:",omitempty"`
+		Skipped  string `json:"-"`
+		hidden   string
+	}
+
+	tests := []struct {
+		field     string
+		name      string
+		omitEmpty bool
+		ok        bool
+	}{
+		{"Plain", "Plain", false, true},
+		{"Renamed", "renamed", false, true},
+		{"Optional", "optional", true, true},
+		{"Unnamed", "Unnamed", true, true},
+		{"Skipped", "", false, false},
+		{"hidden", "", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			field, found := reflect.TypeOf(example{}).FieldByName(test.field)
+			assert.True(t, found)
+			name, omitEmpty, ok := JSONFieldName(field)
+			assert.Equal(t, test.name, name)
+			assert.Equal(t, test.omitEmpty, omitEmpty)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
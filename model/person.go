@@ -1,7 +1,52 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
 type Person struct {
 	Name         string `yaml:"name"`
+	ID           string `yaml:"id" json:",omitempty"`
+	Nickname     string `yaml:"nickname"`
+	Email        string `yaml:"email" json:",omitempty"`
+	Role         string `yaml:"role"`
+	GithubHandle string `yaml:"github_handle"`
 	SlackChannel string `yaml:"slack_channel"`
-	Email        string `yaml:"email"`
+}
+
+// ValidatePeople checks that each person's email, if set, is a well-formed address, and that no email
+// is used by more than one person.
+//
+// Emails are compared ignoring case. People without an email are not checked.
+func ValidatePeople(people []Person) error {
+	var errs []error
+	namesByEmail := make(map[string][]string)
+	for _, person := range people {
+		if person.Email == "" {
+			continue
+		}
+		if address, err := mail.ParseAddress(person.Email); err != nil || address.Address != person.Email {
+			errs = append(errs, fmt.Errorf("person %q has invalid email %q", person.Name, person.Email))
+			continue
+		}
+		email := strings.ToLower(person.Email)
+		namesByEmail[email] = append(namesByEmail[email], fmt.Sprintf("%q", person.Name))
+	}
+
+	emails := make([]string, 0, len(namesByEmail))
+	for email := range namesByEmail {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		if names := namesByEmail[email]; len(names) > 1 {
+			errs = append(errs, fmt.Errorf("email %q is used by more than one person: %s", email, strings.Join(names, ", ")))
+		}
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPersonRoundTripWithEmail(t *testing.T) {
+	source := `
+name: Alice Smith
+nickname: Alice
+email: alice@example.com
+role: Engineer
+github_handle: asmith
+slack_channel: D001
+`
+	var person Person
+	require.NoError(t, yaml.Unmarshal([]byte(source), &person))
+	assert.Equal(t, Person{
+		Name:         "Alice Smith",
+		Nickname:     "Alice",
+		Email:        "alice@example.com",
+		Role:         "Engineer",
+		GithubHandle: "asmith",
+		SlackChannel: "D001",
+	}, person)
+
+	encoded, err := json.Marshal(person)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Name": "Alice Smith",
+		"Nickname": "Alice",
+		"Email": "alice@example.com",
+		"Role": "Engineer",
+		"GithubHandle": "asmith",
+		"SlackChannel": "D001"
+	}`, string(encoded))
+}
+
+func TestPersonRoundTripWithoutEmail(t *testing.T) {
+	source := `
+name: Bob Jones
+role: Manager
+github_handle: bjones
+`
+	var person Person
+	require.NoError(t, yaml.Unmarshal([]byte(source), &person))
+	assert.Equal(t, Person{Name: "Bob Jones", Role: "Manager", GithubHandle: "bjones"}, person)
+
+	encoded, err := json.Marshal(person)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "Email")
+}
+
+func TestPersonRoundTripWithID(t *testing.T) {
+	source := `
+name: Chris Taylor
+id: ctaylor2
+`
+	var person Person
+	require.NoError(t, yaml.Unmarshal([]byte(source), &person))
+	assert.Equal(t, Person{Name: "Chris Taylor", ID: "ctaylor2"}, person)
+
+	encoded, err := json.Marshal(person)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"ID":"ctaylor2"`)
+
+	encoded, err = json.Marshal(Person{Name: "Bob Jones"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "ID")
+}
+
+func TestValidatePeople(t *testing.T) {
+	people := []Person{
+		{Name: "Alice Smith", Email: "alice@example.com"},
+		{Name: "Bob Jones", Email: "bob@example.com"},
+		{Name: "Carol White"},
+		{Name: "Dave Brown"},
+	}
+
+	assert.NoError(t, ValidatePeople(people))
+}
+
+func TestValidatePeopleReportsDuplicateEmails(t *testing.T) {
+	people := []Person{
+		{Name: "Alice Smith", Email: "alice@example.com"},
+		{Name: "Bob Jones", Email: "bob@example.com"},
+		{Name: "Alice Jones", Email: "Alice@example.com"},
+	}
+
+	err := ValidatePeople(people)
+	require.Error(t, err)
+	assert.Equal(t, `email "alice@example.com" is used by more than one person: "Alice Smith", "Alice Jones"`, err.Error())
+}
+
+func TestValidatePeopleReportsMalformedEmails(t *testing.T) {
+	people := []Person{
+		{Name: "Alice Smith", Email: "alice"},
+		{Name: "Bob Jones", Email: "Bob <bob@example.com>"},
+		{Name: "Carol White", Email: "carol@example.com"},
+	}
+
+	err := ValidatePeople(people)
+	require.Error(t, err)
+	assert.Equal(t, `person "Alice Smith" has invalid email "alice"`+"\n"+
+		`person "Bob Jones" has invalid email "Bob <bob@example.com>"`, err.Error())
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// slackChannelName matches a Slack channel name: a leading # followed by up to 80 lowercase letters,
+// digits, hyphens and underscores.
+var slackChannelName = regexp.MustCompile(`^#[a-z0-9_-]{1,80}$`)
+
+// ValidateSlackChannel checks that the given value refers to a Slack channel.
+//
+// The value must be a channel name, such as #platform-devex. An empty value is treated as the channel
+// not being set and is valid.
+func ValidateSlackChannel(channel string) error {
+	if channel == "" || slackChannelName.MatchString(channel) {
+		return nil
+	}
+	return fmt.Errorf("invalid slack channel %q: expected a lowercase channel name starting with #", channel)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSlackChannel(t *testing.T) {
+	for _, channel := range []string{"", "#general", "#platform-devex", "#team_data-2"} {
+		assert.NoError(t, ValidateSlackChannel(channel), channel)
+	}
+}
+
+func TestValidateSlackChannelRejectsInvalidChannels(t *testing.T) {
+	invalid := []string{
+		"general",
+		"#General",
+		"#platform devex",
+		"#platform.devex",
+		"#",
+		"https://example.slack.com/archives/C0UUGL2FJ",
+		"C0UUGL2FJ",
+		"c0uugl2fj",
+	}
+	for _, channel := range invalid {
+		assert.Error(t, ValidateSlackChannel(channel), channel)
+	}
+}
@@ -4,4 +4,6 @@ type Team struct {
 	Name                 string   `yaml:"name"`
 	InternalSlackChannel string   `yaml:"internal_slack_channel"`
 	Members              []string `yaml:"members"`
+	ParentTeam           string   `yaml:"parent_team"`
+	Active               bool     `yaml:"active" default:"true"`
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/model"
+)
+
+// openAPIDocument is the OpenAPI 3 description of the API.
+//
+// The schemas are derived from the model types by reflection, so that new fields are described
+// automatically.
+var openAPIDocument = newCached(func() gin.H {
+	return gin.H{
+		"openapi": "3.0.3",
+		"info": gin.H{
+			"title":   "Teams API",
+			"version": "1.0.0",
+		},
+		"paths": gin.H{
+			"/people": listPath("List people", "Person"),
+			"/teams":  listPath("List teams", "Team"),
+		},
+		"components": gin.H{
+			"schemas": gin.H{
+				"Person": schemaFor(reflect.TypeFor[model.Person]()),
+				"Team":   schemaFor(reflect.TypeFor[model.Team]()),
+			},
+		},
+	}
+})
+
+// listPath describes an endpoint which responds to GET with an array of the named schema.
+func listPath(summary string, schemaName string) gin.H {
+	return gin.H{
+		"get": gin.H{
+			"summary": summary,
+			"responses": gin.H{
+				"200": gin.H{
+					"description": "OK",
+					"content": gin.H{
+						"application/json": gin.H{
+							"schema": gin.H{
+								"type":  "array",
+								"items": gin.H{"$ref": "#/components/schemas/" + schemaName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// schemaFor returns the OpenAPI schema describing the JSON encoding of the given type.
+//
+// Struct properties are named as encoding/json would name them. Fields without omitempty are
+// listed as required.
+func schemaFor(t reflect.Type) gin.H {
+	switch t.Kind() {
+	case reflect.String:
+		return gin.H{"type": "string"}
+	case reflect.Bool:
+		return gin.H{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return gin.H{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return gin.H{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return gin.H{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return gin.H{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		properties := gin.H{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, omitEmpty, ok := model.JSONFieldName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaFor(field.Type)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+		return gin.H{"type": "object", "properties": properties, "required": required}
+	default:
+		return gin.H{}
+	}
+}
+
+// getOpenAPI responds with the OpenAPI 3 description of the API.
+func getOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, openAPIDocument.get())
+}
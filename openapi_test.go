@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOpenAPI(t *testing.T) {
+	response := performRequest(t, http.MethodGet, "/openapi.json")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var document struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &document))
+
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Contains(t, document.Paths, "/people")
+	assert.Contains(t, document.Paths, "/teams")
+
+	require.Contains(t, document.Components.Schemas, "Person")
+	person := document.Components.Schemas["Person"]
+	assert.Equal(t, "object", person.Type)
+	assert.Contains(t, person.Properties, "Name")
+	assert.Contains(t, person.Properties, "Email")
+	assert.Contains(t, person.Required, "Name")
+	assert.NotContains(t, person.Required, "Email")
+
+	require.Contains(t, document.Components.Schemas, "Team")
+	team := document.Components.Schemas["Team"]
+	assert.JSONEq(t, `{"type": "array", "items": {"type": "string"}}`, string(team.Properties["Members"]))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/model"
+)
+
+// orgTeam is a team along with its members resolved to people.
+type orgTeam struct {
+	model.Team
+	People     []model.Person `json:"people"`
+	Unresolved []string       `json:"unresolved"`
+}
+
+// orgChart is every team, including inactive teams, with its members resolved as described by
+// resolveMembers.
+var orgChart = newCached(func() []orgTeam {
+	teams := currentTeams.get()
+	org := make([]orgTeam, 0, len(teams))
+	for _, team := range teams {
+		people, unresolved := resolveMembers(team)
+		org = append(org, orgTeam{Team: team, People: people, Unresolved: unresolved})
+	}
+	return org
+})
+
+// getOrg responds with every team, each with an embedded array of the people it contains and an
+// array of any members which do not refer to a person.
+func getOrg(c *gin.Context) {
+	if notModified(c, jsonRepresentation(c)) {
+		return
+	}
+	writeJSON(c, http.StatusOK, orgChart.get())
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestGetOrg(t *testing.T) {
+	teams := append([]model.Team{
+		{Name: "Legacy", Members: []string{"Carol White", "Dave Brown"}, Active: false},
+	}, testTeams...)
+	seedData(t, testPeople, teams)
+
+	response := performRequest(t, http.MethodGet, "/org")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var org []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &org))
+	require.Len(t, org, 3)
+	assert.Equal(t, "Legacy", org[0]["Name"])
+	assert.Equal(t, false, org[0]["Active"])
+	assert.Equal(t, []any{"Carol White", "Dave Brown"}, org[0]["Members"])
+	assert.Equal(t, []any{"Dave Brown"}, org[0]["unresolved"])
+	assert.Equal(t, []any{}, org[1]["unresolved"])
+
+	var typed []orgTeam
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &typed))
+	assert.Equal(t, []model.Person{testPeople[2]}, typed[0].People)
+	assert.Equal(t, orgTeam{Team: testTeams[0], People: testPeople[:2], Unresolved: []string{}}, typed[1])
+	assert.Equal(t, orgTeam{Team: testTeams[1], People: testPeople[2:], Unresolved: []string{}}, typed[2])
+}
+
+func TestGetOrgIsCached(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	first := orgChart.get()
+	assert.Same(t, &first[0], &orgChart.get()[0])
+
+	resetCaches()
+	assert.NotSame(t, &first[0], &orgChart.get()[0])
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/codegen"
+	"github.com/rvodden/teams/internal/generated_data"
+	"github.com/rvodden/teams/model"
+)
+
+// overridesEnabled reports whether teams may be overridden through the API, read from the
+// TEAMS_ENABLE_OVERRIDES environment variable. The override endpoints are unauthenticated, so they
+// are only registered when the variable is set to true.
+func overridesEnabled() bool {
+	value := os.Getenv("TEAMS_ENABLE_OVERRIDES")
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid TEAMS_ENABLE_OVERRIDES %q, using false", value)
+		return false
+	}
+	return enabled
+}
+
+// teamOverrides holds the in-memory overrides of teams made through PUT /teams/:name, keyed by team name.
+//
+// Overrides only affect the running process; the generated data remains the source of truth and the
+// overrides are lost on restart.
+var teamOverrides = struct {
+	sync.RWMutex
+	teams map[string]model.Team
+}{teams: make(map[string]model.Team)}
+
+// currentTeams is the generated teams with any overrides applied, in the generated order.
+var currentTeams = newCached(func() []model.Team {
+	teamOverrides.RLock()
+	defer teamOverrides.RUnlock()
+	if len(teamOverrides.teams) == 0 {
+		return generated_data.Teams
+	}
+
+	teams := make([]model.Team, len(generated_data.Teams))
+	for i, team := range generated_data.Teams {
+		if override, ok := teamOverrides.teams[team.Name]; ok {
+			team = override
+		}
+		teams[i] = team
+	}
+	return teams
+})
+
+// teamIndexes holds the indexes derived from the teams, which are generated alongside them.
+type teamIndexes struct {
+	teamsByPerson map[string][]string
+	subTeams      map[string][]string
+}
+
+// currentIndexes is the indexes derived from the current teams.
+//
+// The generated indexes are used unless there are overrides, which may have changed team membership
+// or parentage.
+var currentIndexes = newCached(func() teamIndexes {
+	teamOverrides.RLock()
+	overridden := len(teamOverrides.teams) > 0
+	teamOverrides.RUnlock()
+
+	var indexes teamIndexes
+	if overridden {
+		indexes.teamsByPerson, indexes.subTeams, _ = codegen.BuildIndexes(currentTeams.get())
+	} else {
+		indexes.teamsByPerson, indexes.subTeams = generated_data.TeamsByPerson, generated_data.SubTeams
+	}
+	return indexes
+})
+
+// setTeamOverride records an override for the team with the given name, or clears it if team is nil.
+func setTeamOverride(name string, team *model.Team) {
+	teamOverrides.Lock()
+	if team == nil {
+		delete(teamOverrides.teams, name)
+	} else {
+		teamOverrides.teams[name] = *team
+	}
+	teamOverrides.Unlock()
+	resetCaches()
+}
+
+// putTeam overrides fields of the team named in the URL with those given in the JSON request body,
+// and responds with the updated team.
+//
+// Fields which are not in the body keep their current values. The team cannot be renamed.
+func putTeam(c *gin.Context) {
+	team, ok := findTeam(c.Param("name"))
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+
+	name := team.Name
+	// Decoding reuses the capacity of an existing slice, so the members must be copied to avoid
+	// writing into the generated data.
+	team.Members = slices.Clone(team.Members)
+	if err := c.ShouldBindJSON(&team); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "request body must be a JSON team"})
+		return
+	}
+	if team.Name != name {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "team name cannot be changed"})
+		return
+	}
+	if err := validateOverride(team); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setTeamOverride(name, &team)
+	c.IndentedJSON(http.StatusOK, team)
+}
+
+// validateOverride checks an overridden team, along with the hierarchy it is part of, in the same way
+// as the generated teams are checked.
+func validateOverride(team model.Team) error {
+	teams := slices.Clone(currentTeams.get())
+	for i := range teams {
+		if teams[i].Name == team.Name {
+			teams[i] = team
+		}
+	}
+	return errors.Join(
+		codegen.ValidateTeams([]model.Team{team}),
+		codegen.ValidateUniqueSlackChannels(teams),
+		codegen.ValidateHierarchy(teams),
+		codegen.ValidateMembers(generated_data.People, []model.Team{team}),
+	)
+}
+
+// deleteTeamOverride clears any override of the team named in the URL, so that it is served from the
+// generated data again.
+func deleteTeamOverride(c *gin.Context) {
+	team, ok := findTeam(c.Param("name"))
+	if !ok {
+		c.IndentedJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	setTeamOverride(team.Name, nil)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestOverridesDisabledByDefault(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	for _, value := range []string{"", "false", "bogus"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("TEAMS_ENABLE_OVERRIDES", value)
+
+			response := putTeamRequest(t, "Platform", `{"InternalSlackChannel": "#platform-dev"}`)
+			assert.Contains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, response.Code)
+			response = performRequest(t, http.MethodDelete, "/teams/Platform/override")
+			assert.Contains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, response.Code)
+			assert.Equal(t, testTeams[0], getTeam(t, "Platform"))
+		})
+	}
+}
+
+// putTeamRequest sends a PUT request overriding the named team with the given JSON body, clearing
+// any overrides when the test finishes.
+func putTeamRequest(t *testing.T, name string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	t.Cleanup(func() {
+		for _, team := range testTeams {
+			setTeamOverride(team.Name, nil)
+		}
+	})
+	request := httptest.NewRequest(http.MethodPut, "/teams/"+name, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	return serveRequest(t, request)
+}
+
+// getTeam fetches the named team through the API.
+func getTeam(t *testing.T, name string) model.Team {
+	t.Helper()
+	response := performRequest(t, http.MethodGet, "/teams/"+name)
+	require.Equal(t, http.StatusOK, response.Code)
+	var team model.Team
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &team))
+	return team
+}
+
+func TestPutTeamThenGet(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	response := putTeamRequest(t, "platform", `{"InternalSlackChannel": "#platform-dev"}`)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	want := model.Team{Name: "Platform", InternalSlackChannel: "#platform-dev", Members: []string{"Alice Smith", "Bob Jones"}, Active: true}
+	assert.Equal(t, want, getTeam(t, "Platform"))
+	assert.Equal(t, testTeams[0].InternalSlackChannel, "C001", "the generated data is not changed")
+
+	response = performRequest(t, http.MethodGet, "/teams")
+	assert.Contains(t, response.Body.String(), "#platform-dev")
+}
+
+func TestPutTeamUpdatesMembership(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	response := putTeamRequest(t, "Data", `{"InternalSlackChannel": "#data", "Members": ["Carol White", "Alice Smith"]}`)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = performRequest(t, http.MethodGet, "/people/Alice%20Smith/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	var teams []string
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &teams))
+	assert.Equal(t, []string{"Data", "Platform"}, teams)
+}
+
+func TestDeleteTeamOverrideThenGet(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	response := putTeamRequest(t, "Platform", `{"InternalSlackChannel": "#platform-dev"}`)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = performRequest(t, http.MethodDelete, "/teams/Platform/override")
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, testTeams[0], getTeam(t, "Platform"))
+}
+
+func TestPutTeamErrors(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	tests := []struct {
+		name   string
+		team   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "unknown team", team: "Nope", body: `{}`, status: http.StatusNotFound, want: "team not found"},
+		{name: "invalid body", team: "Platform", body: `[`, status: http.StatusBadRequest, want: "JSON team"},
+		{name: "rename", team: "Platform", body: `{"Name": "Infra"}`, status: http.StatusBadRequest, want: "cannot be changed"},
+		{name: "invalid slack channel", team: "Platform", body: `{"InternalSlackChannel": "Platform"}`, status: http.StatusBadRequest, want: "invalid slack channel"},
+		{name: "unknown member", team: "Platform", body: `{"Members": ["Nobody"]}`, status: http.StatusBadRequest, want: `\"Nobody\"`},
+		{name: "cycle", team: "Platform", body: `{"ParentTeam": "Platform"}`, status: http.StatusBadRequest, want: "cycle"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := putTeamRequest(t, test.team, test.body)
+			assert.Equal(t, test.status, response.Code)
+			assert.Contains(t, response.Body.String(), test.want)
+			assert.Equal(t, testTeams[0], getTeam(t, "Platform"))
+			assert.Equal(t, []string{"Alice Smith", "Bob Jones"}, testTeams[0].Members, "the generated data is not changed")
+		})
+	}
+}
+
+func TestDeleteTeamOverrideOfUnknownTeam(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	response := performRequest(t, http.MethodDelete, "/teams/Nope/override")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestPutTeamRejectsSharedSlackChannel(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_ENABLE_OVERRIDES", "true")
+
+	response := putTeamRequest(t, "Platform", `{"InternalSlackChannel": "#data"}`)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	response = putTeamRequest(t, "Data", `{"InternalSlackChannel": "#data"}`)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "used by more than one team")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRateLimit is the number of requests per second each client may make when TEAMS_RATE_LIMIT is
+// not set. Rate limiting is off by default: behind a load balancer whose address is not in
+// TEAMS_TRUSTED_PROXIES, every client would share a single bucket.
+const defaultRateLimit = 0
+
+// rateLimitFromEnv returns the number of requests per second each client may make, read from the
+// TEAMS_RATE_LIMIT environment variable. Zero disables rate limiting. If the variable is not set, or
+// is not a non-negative number, defaultRateLimit is used.
+func rateLimitFromEnv() float64 {
+	value := os.Getenv("TEAMS_RATE_LIMIT")
+	if value == "" {
+		return defaultRateLimit
+	}
+	limit, err := strconv.ParseFloat(value, 64)
+	if err != nil || limit < 0 || math.IsInf(limit, 0) {
+		log.Printf("invalid TEAMS_RATE_LIMIT %q, using %d", value, defaultRateLimit)
+		return defaultRateLimit
+	}
+	return limit
+}
+
+// tokenBucket holds the tokens available to a single client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a concurrency-safe set of token buckets keyed by client.
+//
+// Each bucket holds up to burst tokens and is refilled at rate tokens per second. A request takes
+// one token, and is refused if none is available.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter creates a rate limiter allowing each client the given number of requests per
+// second, with bursts of up to the same number of requests.
+func newRateLimiter(rate float64) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   math.Max(1, rate),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the client's bucket. If the bucket is empty it returns false, along with
+// how long the client should wait before a token becomes available.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep discards, at most once a minute, the buckets which would have refilled completely, so that
+// the number of buckets does not grow without bound. The caller must hold the lock.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// rateLimit returns middleware which limits the rate of requests from each client IP address.
+//
+// Requests over the limit are refused with 429 Too Many Requests, and a Retry-After header giving
+// the number of seconds to wait. A rate of zero disables limiting. Requests to any of the exempt
+// paths are not limited, and do not count towards the client's limit.
+//
+// The client IP address is only taken from headers such as X-Forwarded-For when the request comes
+// from a trusted proxy, so clients cannot escape the limit by setting those headers themselves.
+func rateLimit(rate float64, exemptPaths ...string) gin.HandlerFunc {
+	if rate <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limiter := newRateLimiter(rate)
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+		allowed, wait := limiter.allow(c.ClientIP())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
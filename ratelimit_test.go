@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rateLimit(2))
+	router.GET("/people", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/people", nil)
+		r.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusOK, request("192.0.2.1:1234").Code)
+
+	limited := request("192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.2:1234").Code, "other clients are limited separately")
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(4)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		allowed, _ := limiter.allow("client")
+		assert.True(t, allowed)
+	}
+	allowed, wait := limiter.allow("client")
+	assert.False(t, allowed)
+	assert.Equal(t, 250*time.Millisecond, wait)
+
+	now = now.Add(250 * time.Millisecond)
+	allowed, _ = limiter.allow("client")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1)
+	limiter.now = func() time.Time { return now }
+
+	limiter.allow("client")
+	assert.Len(t, limiter.buckets, 1)
+
+	now = now.Add(2 * time.Minute)
+	limiter.allow("other")
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, "other")
+}
+
+func TestRateLimitFromEnv(t *testing.T) {
+	t.Setenv("TEAMS_RATE_LIMIT", "")
+	assert.Equal(t, float64(defaultRateLimit), rateLimitFromEnv())
+
+	t.Setenv("TEAMS_RATE_LIMIT", "2.5")
+	assert.Equal(t, 2.5, rateLimitFromEnv())
+
+	t.Setenv("TEAMS_RATE_LIMIT", "lots")
+	assert.Equal(t, float64(defaultRateLimit), rateLimitFromEnv())
+}
+
+func TestRateLimitOffByDefault(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_RATE_LIMIT", "")
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	for i := 0; i < 50; i++ {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/teams", nil))
+		require.Equal(t, http.StatusOK, recorder.Code, "request %d", i)
+	}
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_RATE_LIMIT", "1")
+	t.Setenv("TEAMS_TRUSTED_PROXIES", "")
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	codes := make([]int, 0, 5)
+	for i := 0; i < 5; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/teams", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, r)
+		codes = append(codes, recorder.Code)
+	}
+	assert.Equal(t, []int{200, 429, 429, 429, 429}, codes)
+}
+
+func TestRateLimitTrustsConfiguredProxies(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_RATE_LIMIT", "1")
+	t.Setenv("TEAMS_TRUSTED_PROXIES", "192.0.2.0/24")
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/teams", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, r)
+		assert.Equal(t, http.StatusOK, recorder.Code, "clients behind a trusted proxy are limited separately")
+	}
+}
+
+func TestRateLimitExemptsProbesAndScrapes(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+	t.Setenv("TEAMS_RATE_LIMIT", "1")
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	for _, target := range []string{"/healthz", "/metrics", "/healthz", "/metrics", "/teams"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code, target)
+	}
+}
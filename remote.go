@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rvodden/teams/internal/codegen"
+	"github.com/rvodden/teams/internal/generated_data"
+	"github.com/rvodden/teams/model"
+)
+
+// dataURLTimeout is how long fetching the team data from $TEAMS_DATA_URL may take before startup is aborted.
+const dataURLTimeout = 30 * time.Second
+
+// fetchTeams fetches and parses the YAML team data at the given URL.
+func fetchTeams(ctx context.Context, url string) ([]model.Team, error) {
+	ctx, cancel := context.WithTimeout(ctx, dataURLTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := codegen.ParseEntities[model.Team](url, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := codegen.ValidateTeams(teams); err != nil {
+		return nil, fmt.Errorf("invalid teams:\n%w", err)
+	}
+	if err := codegen.ValidateUniqueSlackChannels(teams); err != nil {
+		return nil, fmt.Errorf("shared slack channels:\n%w", err)
+	}
+	if err := codegen.ValidateHierarchy(teams); err != nil {
+		return nil, fmt.Errorf("invalid team hierarchy:\n%w", err)
+	}
+	if err := codegen.ValidateMembers(generated_data.People, teams); err != nil {
+		return nil, fmt.Errorf("invalid team members:\n%w", err)
+	}
+	return teams, nil
+}
+
+// loadRemoteTeams replaces the generated team data with the team data at the given URL, if it is not empty.
+//
+// The indexes derived from the teams are rebuilt, so that every handler is served from the fetched data.
+func loadRemoteTeams(ctx context.Context, url string) error {
+	if url == "" {
+		return nil
+	}
+
+	teams, err := fetchTeams(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to load teams from %s: %w", url, err)
+	}
+
+	generated_data.Teams = teams
+	generated_data.TeamsByPerson, generated_data.SubTeams, generated_data.TeamSizes = codegen.BuildIndexes(teams)
+	resetCaches()
+	return nil
+}
+
+// dataURL returns the URL from which to load the team data instead of using the generated data, if any.
+func dataURL() string {
+	return os.Getenv("TEAMS_DATA_URL")
+}
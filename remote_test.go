@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/internal/generated_data"
+	"github.com/rvodden/teams/model"
+)
+
+const remoteTeamsYAML = `- name: Search
+  internal_slack_channel: "#search"
+  members:
+    - Alice Smith
+    - Carol White
+- name: Search Infra
+  parent_team: Search
+  members:
+    - Bob Jones
+`
+
+// serveTeamsYAML starts a server which serves the given YAML at /teams, with the generated data
+// restored when the test finishes.
+func serveTeamsYAML(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	seedData(t, testPeople, testTeams)
+	seed(t, &generated_data.TeamsByPerson, nil)
+	seed(t, &generated_data.SubTeams, nil)
+	seed(t, &generated_data.TeamSizes, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/teams", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadRemoteTeams(t *testing.T) {
+	server := serveTeamsYAML(t, remoteTeamsYAML)
+
+	require.NoError(t, loadRemoteTeams(context.Background(), server.URL+"/teams"))
+
+	response := performRequest(t, http.MethodGet, "/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	var teams []model.Team
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &teams))
+	assert.Equal(t, []model.Team{
+		{Name: "Search", InternalSlackChannel: "#search", Members: []string{"Alice Smith", "Carol White"}, Active: true},
+		{Name: "Search Infra", Members: []string{"Bob Jones"}, ParentTeam: "Search", Active: true},
+	}, teams)
+
+	response = performRequest(t, http.MethodGet, "/teams/Search/subteams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Search Infra")
+
+	response = performRequest(t, http.MethodGet, "/people/Bob%20Jones/teams")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Search Infra")
+}
+
+func TestLoadRemoteTeamsWithoutURL(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	require.NoError(t, loadRemoteTeams(context.Background(), ""))
+	assert.Equal(t, testTeams, generated_data.Teams)
+}
+
+func TestLoadRemoteTeamsFailures(t *testing.T) {
+	server := serveTeamsYAML(t, "- name: Search\n  members: [Nobody]\n")
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "not found", url: server.URL + "/missing", want: "404"},
+		{name: "invalid data", url: server.URL + "/teams", want: `"Nobody"`},
+		{name: "unreachable", url: "http://127.0.0.1:0/teams", want: "failed to load teams"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := loadRemoteTeams(context.Background(), test.url)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.want)
+			assert.Equal(t, testTeams, generated_data.Teams)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAddr is the address the server listens on when none is configured.
+const defaultAddr = "localhost:8080"
+
+// defaultShutdownTimeout is how long the server waits for in-flight requests when shutting down.
+const defaultShutdownTimeout = 10 * time.Second
+
+// Timeouts applied to each connection, unless overridden by TEAMS_READ_TIMEOUT, TEAMS_WRITE_TIMEOUT or
+// TEAMS_IDLE_TIMEOUT respectively.
+const (
+	// defaultReadTimeout bounds how long reading a request, including its body, may take.
+	defaultReadTimeout = 10 * time.Second
+	// defaultWriteTimeout bounds how long handling a request and writing its response may take.
+	defaultWriteTimeout = 30 * time.Second
+	// defaultIdleTimeout bounds how long a keep-alive connection may wait for its next request.
+	defaultIdleTimeout = 120 * time.Second
+)
+
+// listenAddress returns the address the server should listen on: the value of the -addr flag if
+// given, otherwise the TEAMS_ADDR environment variable, otherwise defaultAddr.
+func listenAddress(flagAddr string) string {
+	if flagAddr != "" {
+		return flagAddr
+	}
+	if envAddr := os.Getenv("TEAMS_ADDR"); envAddr != "" {
+		return envAddr
+	}
+	return defaultAddr
+}
+
+// trustedProxies returns the addresses and CIDR ranges of the proxies whose X-Forwarded-For and
+// X-Real-IP headers are believed, read as a comma-separated list from the TEAMS_TRUSTED_PROXIES
+// environment variable. If the variable is not set no proxy is trusted, and the client IP address
+// is always the address the request came from.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TEAMS_TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// durationFromEnv returns the duration in the named environment variable, in the format accepted by
+// time.ParseDuration. If the variable is not set, or is not a positive duration, defaultValue is used.
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("invalid %s %q, using %s", name, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// newServer creates the HTTP server for the handler, with timeouts read from the environment so that
+// slow or stuck clients cannot tie up connections indefinitely.
+func newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:      handler,
+		ReadTimeout:  durationFromEnv("TEAMS_READ_TIMEOUT", defaultReadTimeout),
+		WriteTimeout: durationFromEnv("TEAMS_WRITE_TIMEOUT", defaultWriteTimeout),
+		IdleTimeout:  durationFromEnv("TEAMS_IDLE_TIMEOUT", defaultIdleTimeout),
+	}
+}
+
+// serve serves HTTP requests on the listener until the context is cancelled, and then shuts the
+// server down gracefully.
+//
+// On shutdown the listener is closed and in-flight requests are given up to shutdownTimeout to
+// complete. An error is returned if the server fails, or if the requests do not complete in time.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagAddr string
+		envAddr  string
+		expected string
+	}{
+		{"defaults", "", "", defaultAddr},
+		{"environment variable", "", "0.0.0.0:9000", "0.0.0.0:9000"},
+		{"flag", ":8081", "", ":8081"},
+		{"flag takes precedence", ":8081", "0.0.0.0:9000", ":8081"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("TEAMS_ADDR", test.envAddr)
+			assert.Equal(t, test.expected, listenAddress(test.flagAddr))
+		})
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	t.Setenv("TEAMS_READ_TIMEOUT", "5s")
+	t.Setenv("TEAMS_WRITE_TIMEOUT", "1m")
+	t.Setenv("TEAMS_IDLE_TIMEOUT", "")
+
+	handler := http.NotFoundHandler()
+	server := newServer(handler)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout)
+	assert.Equal(t, time.Minute, server.WriteTimeout)
+	assert.Equal(t, defaultIdleTimeout, server.IdleTimeout)
+	assert.NotNil(t, server.Handler)
+}
+
+func TestDurationFromEnv(t *testing.T) {
+	t.Setenv("TEAMS_TEST_TIMEOUT", "")
+	assert.Equal(t, time.Second, durationFromEnv("TEAMS_TEST_TIMEOUT", time.Second))
+
+	t.Setenv("TEAMS_TEST_TIMEOUT", "250ms")
+	assert.Equal(t, 250*time.Millisecond, durationFromEnv("TEAMS_TEST_TIMEOUT", time.Second))
+
+	t.Setenv("TEAMS_TEST_TIMEOUT", "soon")
+	assert.Equal(t, time.Second, durationFromEnv("TEAMS_TEST_TIMEOUT", time.Second))
+
+	t.Setenv("TEAMS_TEST_TIMEOUT", "-5s")
+	assert.Equal(t, time.Second, durationFromEnv("TEAMS_TEST_TIMEOUT", time.Second))
+}
+
+func TestServeDrainsInFlightRequestsOnShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, server, listener, time.Second)
+	}()
+
+	responded := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		responded <- string(body)
+	}()
+
+	<-started
+	cancel()
+	close(release)
+
+	assert.Equal(t, "done", <-responded)
+	assert.NoError(t, <-served)
+}
+
+func TestServeTimesOutWaitingForRequests(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, server, listener, 10*time.Millisecond)
+	}()
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err == nil {
+			response.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+}
+
+func TestTrustedProxies(t *testing.T) {
+	t.Setenv("TEAMS_TRUSTED_PROXIES", "")
+	assert.Empty(t, trustedProxies())
+
+	t.Setenv("TEAMS_TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1,")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, trustedProxies())
+}
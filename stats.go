@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+// teamSize names a team along with its number of members.
+type teamSize struct {
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+}
+
+// stats holds aggregate numbers computed from the generated data.
+type stats struct {
+	People          int       `json:"people"`
+	Teams           int       `json:"teams"`
+	AverageTeamSize float64   `json:"average_team_size"`
+	LargestTeam     *teamSize `json:"largest_team"`
+}
+
+// dataStats are the statistics for the generated data.
+//
+// The average team size is zero when there are no teams, and the largest team is nil. If several
+// teams share the largest size, the first of them is reported.
+var dataStats = newCached(func() stats {
+	result := stats{
+		People: len(generated_data.People),
+		Teams:  len(currentTeams.get()),
+	}
+
+	totalMembers := 0
+	for _, team := range currentTeams.get() {
+		totalMembers += len(team.Members)
+		if result.LargestTeam == nil || len(team.Members) > result.LargestTeam.Members {
+			result.LargestTeam = &teamSize{Name: team.Name, Members: len(team.Members)}
+		}
+	}
+	if result.Teams > 0 {
+		result.AverageTeamSize = float64(totalMembers) / float64(result.Teams)
+	}
+
+	return result
+})
+
+// getStats responds with aggregate statistics about the people and teams.
+func getStats(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, dataStats.get())
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/model"
+)
+
+func TestGetStats(t *testing.T) {
+	teams := []model.Team{
+		{Name: "Platform", Members: []string{"Alice Smith", "Bob Jones"}},
+		{Name: "Data", Members: []string{"Carol White", "Alice Smith", "Bob Jones"}},
+		{Name: "Empty"},
+		{Name: "Security", Members: []string{"Carol White"}},
+	}
+	seedData(t, testPeople, teams)
+
+	response := performRequest(t, http.MethodGet, "/stats")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{
+		"people": 3,
+		"teams": 4,
+		"average_team_size": 1.5,
+		"largest_team": {"name": "Data", "members": 3}
+	}`, response.Body.String())
+}
+
+func TestGetStatsWithoutTeams(t *testing.T) {
+	seedData(t, testPeople, []model.Team{})
+
+	response := performRequest(t, http.MethodGet, "/stats")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{
+		"people": 3,
+		"teams": 0,
+		"average_team_size": 0,
+		"largest_team": null
+	}`, response.Body.String())
+}
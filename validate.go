@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/codegen"
+	"github.com/rvodden/teams/model"
+)
+
+// maxValidateBodySize is the largest request body accepted by POST /teams/validate, in bytes.
+const maxValidateBodySize = 1 << 20
+
+// teamProblem is a single problem found with a proposed team.
+type teamProblem struct {
+	Team    string `json:"team"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// teamValidation is the result of validating proposed teams.
+type teamValidation struct {
+	Valid    bool          `json:"valid"`
+	Problems []teamProblem `json:"problems"`
+}
+
+// validateTeam checks a proposed team against the served people and the expected formats, and
+// returns the problems found.
+func validateTeam(team model.Team) []teamProblem {
+	var problems []teamProblem
+	if team.Name == "" {
+		problems = append(problems, teamProblem{Field: "name", Message: "name is required"})
+	}
+	if err := model.ValidateSlackChannel(team.InternalSlackChannel); err != nil {
+		problems = append(problems, teamProblem{Team: team.Name, Field: "internal_slack_channel", Message: err.Error()})
+	}
+	_, unresolved := resolveMembers(team)
+	for _, member := range unresolved {
+		problems = append(problems, teamProblem{Team: team.Name, Field: "members", Message: fmt.Sprintf("unknown member %q", member)})
+	}
+	return problems
+}
+
+// parseProposedTeams parses one team, or a list of teams, from a request body with the given content type.
+//
+// YAML is parsed in the same way as the source files, using their field names. Anything else is
+// parsed as JSON, using the field names of the JSON responses.
+func parseProposedTeams(contentType string, body []byte) ([]model.Team, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return codegen.ParseEntities[model.Team]("request body", body)
+	}
+
+	var teams []model.Team
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := decodeStrictJSON(trimmed, &teams); err != nil {
+			return nil, err
+		}
+		return teams, nil
+	}
+	var team model.Team
+	if err := decodeStrictJSON(body, &team); err != nil {
+		return nil, err
+	}
+	return []model.Team{team}, nil
+}
+
+// decodeStrictJSON decodes a single JSON value from data into v, rejecting fields which v does not
+// have, so that a misspelt field is reported rather than silently ignored.
+func decodeStrictJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// postTeamsValidate checks the team, or teams, in the request body without changing any data, and
+// responds with the problems found.
+//
+// The body is parsed as YAML if its content type says so, and as JSON otherwise. A body which cannot
+// be parsed is rejected with 400 Bad Request.
+func postTeamsValidate(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxValidateBodySize))
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+	teams, err := parseProposedTeams(c.ContentType(), body)
+	if err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to parse teams: %v", err)})
+		return
+	}
+	if len(teams) == 0 {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "no teams given"})
+		return
+	}
+
+	result := teamValidation{Problems: []teamProblem{}}
+	for _, team := range teams {
+		result.Problems = append(result.Problems, validateTeam(team)...)
+	}
+	result.Valid = len(result.Problems) == 0
+	c.IndentedJSON(http.StatusOK, result)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postValidate sends the body to POST /teams/validate with the given content type.
+func postValidate(t *testing.T, contentType string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/teams/validate", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	return serveRequest(t, request)
+}
+
+// decodeValidation decodes a successful validation response.
+func decodeValidation(t *testing.T, response *httptest.ResponseRecorder) teamValidation {
+	t.Helper()
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	var result teamValidation
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+	return result
+}
+
+func TestPostTeamsValidateCleanTeam(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := postValidate(t, "application/json", `{"Name": "Search", "InternalSlackChannel": "#search", "Members": ["Alice Smith"]}`)
+	assert.Equal(t, teamValidation{Valid: true, Problems: []teamProblem{}}, decodeValidation(t, response))
+
+	response = postValidate(t, "application/yaml", "name: Search\ninternal_slack_channel: '#search'\nmembers: [Carol White]\n")
+	assert.Equal(t, teamValidation{Valid: true, Problems: []teamProblem{}}, decodeValidation(t, response))
+}
+
+func TestPostTeamsValidateUnknownMember(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	response := postValidate(t, "text/yaml; charset=utf-8", "- name: Search\n  internal_slack_channel: search\n  members: [Alice Smith, Dave Brown]\n")
+	result := decodeValidation(t, response)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Problems, 2)
+	assert.Equal(t, teamProblem{Team: "Search", Field: "internal_slack_channel", Message: result.Problems[0].Message}, result.Problems[0])
+	assert.Equal(t, teamProblem{Team: "Search", Field: "members", Message: `unknown member "Dave Brown"`}, result.Problems[1])
+}
+
+func TestPostTeamsValidateMalformedInput(t *testing.T) {
+	seedData(t, testPeople, testTeams)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"malformed JSON", "application/json", `{"Name": `},
+		{"unknown JSON field", "application/json", `{"Name":"X","Membrs":["Nobody"]}`},
+		{"unknown JSON field in list", "application/json", `[{"Name":"X","Membrs":["Nobody"]}]`},
+		{"malformed YAML", "application/yaml", "name: [Search\n"},
+		{"unknown YAML field", "application/yaml", "name: Search\nmembrs: []\n"},
+		{"empty", "application/yaml", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := postValidate(t, test.contentType, test.body)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Contains(t, response.Body.String(), "error")
+		})
+	}
+}
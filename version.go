@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+// version is the version of the binary. It can be set at build time with
+// -ldflags "-X main.version=...", and otherwise is taken from the build information.
+var version = ""
+
+// versionInfo describes the binary and the data it was built with.
+type versionInfo struct {
+	Version        string `json:"version"`
+	Revision       string `json:"revision,omitempty"`
+	GeneratedAt    string `json:"generated_at"`
+	SourceChecksum string `json:"source_checksum"`
+}
+
+// buildVersion returns the version of the binary and the VCS revision it was built from, if known.
+func buildVersion() (string, string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version, ""
+	}
+
+	buildVersion, revision := info.Main.Version, ""
+	if version != "" {
+		buildVersion = version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			revision = setting.Value
+		}
+	}
+	return buildVersion, revision
+}
+
+// getVersion responds with the version of the binary along with when and from what source data the
+// generated data was generated.
+func getVersion(c *gin.Context) {
+	buildVersion, revision := buildVersion()
+	c.IndentedJSON(http.StatusOK, versionInfo{
+		Version:        buildVersion,
+		Revision:       revision,
+		GeneratedAt:    generated_data.GeneratedAt,
+		SourceChecksum: generated_data.SourceChecksum,
+	})
+}
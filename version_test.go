@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rvodden/teams/internal/generated_data"
+)
+
+func TestGetVersion(t *testing.T) {
+	seed(t, &generated_data.GeneratedAt, "2024-05-01T12:00:00Z")
+	seed(t, &generated_data.SourceChecksum, "abc123")
+	seed(t, &version, "1.2.3")
+
+	response := performRequest(t, http.MethodGet, "/version")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "1.2.3", body["version"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", body["generated_at"])
+	assert.Equal(t, "abc123", body["source_checksum"])
+}